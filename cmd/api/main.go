@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os/signal"
 	"syscall"
 	"time"
@@ -17,19 +18,34 @@ func main() {
 	_ = godotenv.Load()
 	cfg := config.LoadEnv()
 
+	mode := flag.String("mode", string(cfg.Mode), "roles to run: api, worker or all (overrides RUN_MODE)")
+	flag.Parse()
+	cfg.Mode = config.RunMode(*mode)
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
 	log := logger.Init(cfg)
 	log.Info().Msg("logger initialized")
 
+	if err := cfg.Mode.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid run mode")
+	}
+	log.Info().Str("mode", string(cfg.Mode)).Msg("starting")
+
 	container, err := app.NewContainer(ctx, cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize dependencies")
 	}
 
 	// start consumer runs in seperate go routine (for link )
-	app.StartConsumer(ctx, container)
+	if cfg.Mode.Worker() {
+		app.StartConsumer(ctx, container)
+	}
+	if cfg.Mode.API() {
+		app.StartScheduler(ctx, container)
+		app.StartReconciler(ctx, container)
+	}
 	router := app.NewRouter(container)
 
 	srv := server.New(router, cfg.Port, log)
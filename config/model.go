@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type RabbitMQConfig struct {
 	BrokerLink   string `mapstructure:"broker_link"`
 	ExchangeName string `mapstructure:"exchange_name"`
@@ -7,6 +13,15 @@ type RabbitMQConfig struct {
 	QueueName    string `mapstructure:"queue_name"`
 	RoutingKey   string `mapstructure:"routing_key"`
 	WorkerCount  int    `mapstructure:"worker_count"`
+	MaxPriority  int    `mapstructure:"max_priority"` // x-max-priority of the queue, 0 declares a plain FIFO queue
+
+	PublishConfirmTimeout time.Duration `mapstructure:"publish_confirm_timeout"` // how long a publish waits for the broker confirm
+
+	RetryMaxAttempts int           `mapstructure:"retry_max_attempts"` // scrape attempts before a transient failure fails the job
+	RetryBaseDelay   time.Duration `mapstructure:"retry_base_delay"`   // backoff before the first retry, doubled for each next one
+
+	DeadLetterExchange string `mapstructure:"dead_letter_exchange"` // where failed messages go, empty drops them
+	DeadLetterQueue    string `mapstructure:"dead_letter_queue"`
 }
 
 type ClientConfig struct {
@@ -16,3 +31,95 @@ type ClientConfig struct {
 	SecretKey  string `mapstructure:"secret_key"`
 	BucketName string `mapstructure:"bucket_name"`
 }
+
+type WebhookConfig struct {
+	Secret      string        `mapstructure:"secret"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	MaxAttempts int           `mapstructure:"max_attempts"`
+}
+
+type CacheConfig struct {
+	TTL    time.Duration `mapstructure:"ttl"`     // how long results are kept, 0 disables the cache
+	MaxAge time.Duration `mapstructure:"max_age"` // default freshness accepted on submit
+}
+
+// RateLimitConfig sets the token buckets for submissions. A zero rate or
+// burst disables that limit.
+type RateLimitConfig struct {
+	IPPerMinute  int `mapstructure:"ip_per_minute"`
+	IPBurst      int `mapstructure:"ip_burst"`
+	KeyPerMinute int `mapstructure:"key_per_minute"`
+	KeyBurst     int `mapstructure:"key_burst"`
+}
+
+type CaptchaConfig struct {
+	Provider  string  `mapstructure:"provider"` // turnstile, hcaptcha or recaptcha; empty disables verification
+	Secret    string  `mapstructure:"secret"`
+	VerifyURL string  `mapstructure:"verify_url"` // overrides the provider's siteverify endpoint
+	Action    string  `mapstructure:"action"`     // reCAPTCHA v3 only, the action tokens must be issued for
+	MinScore  float64 `mapstructure:"min_score"`  // reCAPTCHA v3 only
+}
+
+// PolitenessConfig spaces out scrapes of the same host across all workers.
+type PolitenessConfig struct {
+	Concurrency int                  `mapstructure:"concurrency"` // concurrent scrapes per host, 0 = unlimited
+	Delay       time.Duration        `mapstructure:"delay"`       // minimum time between scrapes of a host
+	Overrides   map[string]HostLimit `mapstructure:"overrides"`
+}
+
+type HostLimit struct {
+	Concurrency int           `mapstructure:"concurrency"`
+	Delay       time.Duration `mapstructure:"delay"`
+}
+
+// For returns the limit of host, taken from the most specific override
+// matching host or one of its parent domains.
+func (c PolitenessConfig) For(host string) HostLimit {
+	for h := host; h != ""; {
+		if limit, ok := c.Overrides[h]; ok {
+			return limit
+		}
+		_, parent, found := strings.Cut(h, ".")
+		if !found {
+			break
+		}
+		h = parent
+	}
+	return HostLimit{Concurrency: c.Concurrency, Delay: c.Delay}
+}
+
+// ReconcilerConfig sets when pending and processing jobs count as stuck.
+type ReconcilerConfig struct {
+	Interval      time.Duration `mapstructure:"interval"`       // how often to look for stuck jobs, 0 disables the reconciler
+	PendingAge    time.Duration `mapstructure:"pending_age"`    // pending longer than this is re-published, keep it above the longest queue wait
+	ProcessingAge time.Duration `mapstructure:"processing_age"` // processing longer than this is failed
+}
+
+// RunMode selects the roles a process plays: the HTTP API with the
+// monitor scheduler and the reconciler, the queue workers, or both.
+type RunMode string
+
+const (
+	ModeAll    RunMode = "all"
+	ModeAPI    RunMode = "api"
+	ModeWorker RunMode = "worker"
+)
+
+// Validate rejects unknown modes.
+func (m RunMode) Validate() error {
+	switch m {
+	case ModeAll, ModeAPI, ModeWorker:
+		return nil
+	}
+	return fmt.Errorf("unknown run mode %q, want api, worker or all", string(m))
+}
+
+// API reports whether the process serves the API.
+func (m RunMode) API() bool {
+	return m == ModeAll || m == ModeAPI
+}
+
+// Worker reports whether the process consumes scrape jobs.
+func (m RunMode) Worker() bool {
+	return m == ModeAll || m == ModeWorker
+}
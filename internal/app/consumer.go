@@ -6,6 +6,8 @@ import (
 )
 
 func StartConsumer(ctx context.Context, c *Container) {
+	go c.ScrapeWk.ListenForCancels(ctx)
+
 	go func() {
 		log.Println("starting background consumer")
 
@@ -15,3 +17,18 @@ func StartConsumer(ctx context.Context, c *Container) {
 		}
 	}()
 }
+
+// StartScheduler runs the monitor scheduler until ctx is done. Every
+// replica runs it, only the lock holder fires monitors.
+func StartScheduler(ctx context.Context, c *Container) {
+	go c.scheduler.Run(ctx)
+}
+
+// StartReconciler runs the job reconciler until ctx is done, unless it is
+// disabled. Like the scheduler only the lock holder acts.
+func StartReconciler(ctx context.Context, c *Container) {
+	if c.cfg.Reconciler.Interval <= 0 {
+		return
+	}
+	go c.reconciler.Run(ctx)
+}
@@ -2,32 +2,52 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/Alkush-Pipania/Scrapper/config"
 	infraBrowserless "github.com/Alkush-Pipania/Scrapper/internal/infra/browserless"
 	infraYouTube "github.com/Alkush-Pipania/Scrapper/internal/infra/youtube"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/auth"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/captcha"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/dlq"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/monitor"
 	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape"
 	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape/engine"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/urls"
 	"github.com/Alkush-Pipania/Scrapper/pkg/browserless"
+	"github.com/Alkush-Pipania/Scrapper/pkg/hcaptcha"
 	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+	"github.com/Alkush-Pipania/Scrapper/pkg/recaptcha"
 	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
 	"github.com/Alkush-Pipania/Scrapper/pkg/s3"
 	"github.com/Alkush-Pipania/Scrapper/pkg/turnstile"
+	"github.com/Alkush-Pipania/Scrapper/pkg/webhook"
 	"github.com/Alkush-Pipania/Scrapper/pkg/youtube"
-	"github.com/rabbitmq/amqp091-go"
 )
 
 type Container struct {
-	ScrapeHandler *scrape.Handler
-	consumer      *mq.Consumer
-	RMQConn       *amqp091.Connection
-	ScrapeWk      *scrape.ScrapeWorker
+	ScrapeHandler  *scrape.Handler
+	AuthHandler    *auth.Handler
+	MonitorHandler *monitor.Handler
+	URLsHandler    *urls.Handler
+	DLQHandler     *dlq.Handler
+	Verifier       captcha.Verifier
+	consumer       *mq.Consumer
+	RMQConn        *mq.Connection
+	ScrapeWk       *scrape.ScrapeWorker
+	notifier       *scrape.Notifier
+	scheduler      *monitor.Scheduler
+	reconciler     *scrape.Reconciler
+	rds            *redis.Client
+	cfg            *config.Config
 }
 
+// NewContainer builds what the roles of cfg.Mode need: the API does not
+// touch Browserless, S3 or YouTube and the worker has no handlers.
 func NewContainer(ctx context.Context, cfg *config.Config) (*Container, error) {
-	// setup rabbit mq connection and consumer
-	rmqpConn, consumer, err := setupRabbitMQ(&cfg.RabbitMQ)
+	// setup rabbit mq connection
+	rmqpConn, err := mq.Dial(&cfg.RabbitMQ)
 	if err != nil {
 		return nil, err
 	}
@@ -38,17 +58,74 @@ func NewContainer(ctx context.Context, cfg *config.Config) (*Container, error) {
 	}
 
 	// setup rabbitmq publisher
-	pbh, err := mq.NewPublisher(rmqpConn, cfg.RabbitMQ.ExchangeName, cfg.RabbitMQ.RoutingKey)
+	pbh, err := mq.NewPublisher(rmqpConn, cfg.RabbitMQ.ExchangeName, cfg.RabbitMQ.RoutingKey, cfg.RabbitMQ.PublishConfirmTimeout)
 	if err != nil {
 		return nil, err
 	}
 
-	tsClient := turnstile.New(cfg.TurnstileSecret)
+	webhookClient := webhook.New(cfg.Webhook.Timeout, cfg.Webhook.MaxAttempts)
+	notifier := scrape.NewNotifier(rds, webhookClient, cfg.Webhook.Secret)
+
+	c := &Container{
+		RMQConn:  rmqpConn,
+		notifier: notifier,
+		rds:      rds,
+		cfg:      cfg,
+	}
+	if cfg.Mode.API() {
+		if err := c.setupAPI(pbh); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Mode.Worker() {
+		if err := c.setupWorker(ctx, pbh); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// setupAPI builds the HTTP handlers and the background jobs that run next
+// to them.
+func (c *Container) setupAPI(pbh *mq.Publisher) error {
+	cfg := c.cfg
+
+	verifier, err := newVerifier(cfg.Captcha)
+	if err != nil {
+		return err
+	}
+
+	scrapeService := scrape.NewService(c.rds, pbh, c.notifier, scrape.ServiceConfig{
+		Cache:          cfg.Cache,
+		IdempotencyTTL: cfg.IdempotencyTTL,
+	})
+
+	monitorService := monitor.NewService(c.rds)
+	urlsService := urls.NewService(c.rds)
+	authService := auth.NewService(c.rds)
+	dlqService := dlq.NewService(mq.NewDeadLetters(c.RMQConn, pbh, &cfg.RabbitMQ))
+
+	c.ScrapeHandler = scrape.NewHandler(scrapeService)
+	c.AuthHandler = auth.NewHandler(authService)
+	c.MonitorHandler = monitor.NewHandler(monitorService)
+	c.URLsHandler = urls.NewHandler(urlsService)
+	c.DLQHandler = dlq.NewHandler(dlqService)
+	c.Verifier = verifier
+	c.scheduler = monitor.NewScheduler(c.rds, scrapeService, cfg.MonitorTick)
+	c.reconciler = scrape.NewReconciler(c.rds, pbh, c.notifier, c.RMQConn,
+		[]string{cfg.RabbitMQ.QueueName, mq.DeferQueue(&cfg.RabbitMQ)}, cfg.Reconciler)
+	return nil
+}
+
+// setupWorker builds the scrape engine and the queue consumer.
+func (c *Container) setupWorker(ctx context.Context, pbh *mq.Publisher) error {
+	cfg := c.cfg
+
 	ytS := youtube.NewClient(cfg.YouTubeAPIKey)
 	browserClient := browserless.New(cfg.BrowserlessURL, cfg.BrowserlessToken)
 	s3Client, err := s3.NewClient(ctx, cfg.S3Client)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	browserAdapter := infraBrowserless.NewAdapter(browserClient)
@@ -56,31 +133,55 @@ func NewContainer(ctx context.Context, cfg *config.Config) (*Container, error) {
 
 	scrapS := engine.New(browserAdapter, s3Client, youtubeAdapter, &http.Client{})
 
-	scrapeWorker := scrape.NewScrapeWorker(rds, scrapS)
-	scrapeService := scrape.NewService(rds, pbh)
-	scrapeHandler := scrape.NewHandler(scrapeService, tsClient)
-	return &Container{
-		ScrapeHandler: scrapeHandler,
-		consumer:      consumer,
-		RMQConn:       rmqpConn,
-		ScrapeWk:      scrapeWorker,
-	}, nil
+	consumer, err := newConsumer(c.RMQConn, pbh, &cfg.RabbitMQ)
+	if err != nil {
+		return err
+	}
+
+	c.ScrapeWk = scrape.NewScrapeWorker(c.rds, scrapS, c.notifier, pbh, scrape.WorkerConfig{
+		CacheTTL:        cfg.Cache.TTL,
+		ChangeRetention: cfg.ChangeRetention,
+		Politeness:      cfg.Politeness,
+		RetryQueues:     mq.RetryQueues(&cfg.RabbitMQ),
+		DeferQueue:      mq.DeferQueue(&cfg.RabbitMQ),
+	})
+	c.consumer = consumer
+	return nil
 }
 
-func setupRabbitMQ(cfg *config.RabbitMQConfig) (*amqp091.Connection, *mq.Consumer, error) {
-	rmqpConn, err := mq.NewConn(cfg)
-	if err != nil {
-		return nil, nil, err
+func newConsumer(conn *mq.Connection, pbh *mq.Publisher, cfg *config.RabbitMQConfig) (*mq.Consumer, error) {
+	// the exchange is only declared together with its queue
+	deadLetter := cfg.DeadLetterExchange
+	if cfg.DeadLetterQueue == "" {
+		deadLetter = ""
 	}
-	if err := mq.SetupTopology(rmqpConn, cfg); err != nil {
-		return nil, nil, err
+	return mq.NewConsumer(conn, pbh, cfg.QueueName, deadLetter, cfg.WorkerCount)
+}
+
+// newVerifier builds the configured human-verification provider, nil when
+// verification is disabled.
+func newVerifier(cfg config.CaptchaConfig) (captcha.Verifier, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
 	}
-	consumer, err := mq.NewConsumer(rmqpConn, cfg.QueueName, cfg.WorkerCount)
-	if err != nil {
-		return nil, nil, err
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("captcha provider %q requires CAPTCHA_SECRET", cfg.Provider)
 	}
-	return rmqpConn, consumer, nil
 
+	switch cfg.Provider {
+	case "turnstile":
+		return turnstile.New(cfg.Secret, cfg.VerifyURL), nil
+	case "hcaptcha":
+		return hcaptcha.New(cfg.Secret, cfg.VerifyURL), nil
+	case "recaptcha":
+		if cfg.Action == "" {
+			return nil, fmt.Errorf("captcha provider %q requires RECAPTCHA_ACTION", cfg.Provider)
+		}
+		return recaptcha.New(cfg.Secret, cfg.VerifyURL, cfg.Action, cfg.MinScore), nil
+	default:
+		return nil, fmt.Errorf("unknown captcha provider %q", cfg.Provider)
+	}
 }
 
 func (c *Container) Shutdown(ctx context.Context) error {
@@ -88,6 +189,10 @@ func (c *Container) Shutdown(ctx context.Context) error {
 		_ = c.consumer.Shutdown(ctx)
 	}
 
+	if c.notifier != nil {
+		_ = c.notifier.Wait(ctx)
+	}
+
 	if c.RMQConn != nil {
 		_ = c.RMQConn.Close()
 	}
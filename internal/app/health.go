@@ -0,0 +1,24 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+)
+
+type healthResponse struct {
+	Status   string `json:"status"`
+	RabbitMQ string `json:"rabbitmq"`
+}
+
+// health reports 503 while RabbitMQ is reconnecting so load balancers stop
+// routing submissions that cannot be enqueued.
+func health(c *Container) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.RMQConn.Connected() {
+			httpx.WriteJSON(w, http.StatusServiceUnavailable, healthResponse{Status: "unavailable", RabbitMQ: "disconnected"})
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK, healthResponse{Status: "ok", RabbitMQ: "connected"})
+	}
+}
@@ -3,7 +3,14 @@ package app
 import (
 	"net/http"
 
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/auth"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/captcha"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/dlq"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/monitor"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/ratelimit"
 	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/urls"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -12,26 +19,50 @@ import (
 func NewRouter(container *Container) http.Handler {
 	r := chi.NewRouter()
 
+	if container.cfg.TrustProxy {
+		r.Use(middleware.RealIP)
+	}
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Turnstile-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", captcha.TokenHeader, captcha.LegacyTokenHeader, "Idempotency-Key", auth.APIKeyHeader},
+		ExposedHeaders:   []string{"Link", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		httpx.WriteError(w, http.StatusNotFound, httpx.CodeNotFound, "Not found")
 	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+	})
+
+	r.Get("/health", health(container))
+
+	// a worker process only answers health checks
+	if !container.cfg.Mode.API() {
+		return r
+	}
 
 	r.Group(func(r chi.Router) {
 		r.Route("/api/v1", func(v1Route chi.Router) {
-			v1Route.Mount("/scrape", scrape.Routes(container.ScrapeHandler))
+			v1Route.Group(func(r chi.Router) {
+				r.Use(container.AuthHandler.APIKey(container.cfg.RequireAPIKey))
+				r.Use(ratelimit.Middleware(container.rds, container.cfg.RateLimit))
+				r.Mount("/scrape", scrape.Routes(container.ScrapeHandler, captcha.Middleware(container.Verifier)))
+				r.Mount("/monitors", monitor.Routes(container.MonitorHandler))
+				r.Mount("/urls", urls.Routes(container.URLsHandler))
+			})
+
+			v1Route.Route("/admin", func(r chi.Router) {
+				r.Use(auth.AdminOnly(container.cfg.AdminToken))
+				r.Mount("/keys", auth.AdminRoutes(container.AuthHandler))
+				r.Mount("/dlq", dlq.AdminRoutes(container.DLQHandler))
+			})
 		})
 	})
 
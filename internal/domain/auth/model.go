@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+type APIKey struct {
+	ID             string          `json:"id"`
+	Hash           string          `json:"hash"`
+	Owner          string          `json:"owner"`
+	DailyQuota     int             `json:"daily_quota"`               // 0 means unlimited
+	MonthlyQuota   int             `json:"monthly_quota"`             // 0 means unlimited
+	RatePerMinute  int             `json:"rate_per_minute,omitempty"` // 0 uses the server default
+	RateBurst      int             `json:"rate_burst,omitempty"`      // 0 uses the server default
+	AllowedOptions *AllowedOptions `json:"allowed_options,omitempty"`
+	Disabled       bool            `json:"disabled"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// AllowedOptions restricts the scrape options a key may request. A nil
+// value on the key allows everything, empty lists allow every value.
+type AllowedOptions struct {
+	Strategies  []scrape.Strategy       `json:"strategies,omitempty"`
+	Screenshots []scrape.ScreenshotMode `json:"screenshots,omitempty"`
+	IncludeHTML bool                    `json:"include_html"`
+}
+
+// Permits checks the requested options against the key's allowed options.
+func (k *APIKey) Permits(opts scrape.ScrapeOptions) error {
+	allowed := k.AllowedOptions
+	if allowed == nil {
+		return nil
+	}
+	opts = opts.WithDefaults()
+
+	if len(allowed.Strategies) > 0 && !slices.Contains(allowed.Strategies, opts.Strategy) {
+		return fmt.Errorf("%w: strategy %q", ErrOptionNotAllowed, opts.Strategy)
+	}
+	if len(allowed.Screenshots) > 0 && !slices.Contains(allowed.Screenshots, opts.Screenshot) {
+		return fmt.Errorf("%w: screenshot %q", ErrOptionNotAllowed, opts.Screenshot)
+	}
+	if opts.IncludeHTML && !allowed.IncludeHTML {
+		return fmt.Errorf("%w: include_html", ErrOptionNotAllowed)
+	}
+	return nil
+}
+
+type Usage struct {
+	Daily   int `json:"daily"`
+	Monthly int `json:"monthly"`
+}
+
+// QuotaError reports which quota a submission would exceed.
+type QuotaError struct {
+	Period  string // "daily" or "monthly"
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota of %d submissions exceeded", e.Period, e.Limit)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+var (
+	ErrKeyNotFound      = errors.New("api key not found")
+	ErrKeyDisabled      = errors.New("api key disabled")
+	ErrQuotaExceeded    = errors.New("quota exceeded")
+	ErrOptionNotAllowed = errors.New("option not allowed for this api key")
+	// ErrAPIKeyRequired guards reads that list what a caller submitted.
+	// Anonymous callers cannot be told apart, so they get no history.
+	ErrAPIKeyRequired = errors.New("api key required")
+)
+
+type ctxKey struct{}
+
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, ctxKey{}, key)
+}
+
+// APIKeyFromContext returns the authenticated key, nil for anonymous calls.
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(ctxKey{}).(*APIKey)
+	return key
+}
+
+// KeyIDFrom returns the ID of the authenticated key, the owner of whatever
+// the caller creates. It is empty for anonymous calls.
+func KeyIDFrom(ctx context.Context) string {
+	if key := APIKeyFromContext(ctx); key != nil {
+		return key.ID
+	}
+	return ""
+}
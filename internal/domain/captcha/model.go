@@ -0,0 +1,8 @@
+package captcha
+
+import "context"
+
+// Verifier checks a human-verification token issued to a browser client.
+type Verifier interface {
+	Verify(ctx context.Context, token, ip string) error
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/cron"
+)
+
+// Monitor scrapes a URL on a schedule, either every Interval or on the
+// activations of a Cron expression (evaluated in UTC).
+type Monitor struct {
+	ID             string               `json:"id"`
+	URL            string               `json:"url"`
+	Interval       string               `json:"interval,omitempty"` // e.g. "1h"
+	Cron           string               `json:"cron,omitempty"`
+	Options        scrape.ScrapeOptions `json:"options"`
+	Priority       scrape.Priority      `json:"priority,omitempty"`
+	CallbackURL    string               `json:"callback_url,omitempty"`
+	CallbackSecret string               `json:"callback_secret,omitempty"`
+	APIKeyID       string               `json:"api_key_id,omitempty"`
+	Paused         bool                 `json:"paused"`
+
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastJobID string     `json:"last_job_id,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Next returns the first run strictly after t.
+func (m *Monitor) Next(t time.Time) (time.Time, error) {
+	if m.Cron != "" {
+		sched, err := cron.Parse(m.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := sched.Next(t.UTC())
+		if next.IsZero() {
+			return time.Time{}, ErrNeverRuns
+		}
+		return next, nil
+	}
+
+	interval, err := time.ParseDuration(m.Interval)
+	if err != nil || interval <= 0 {
+		return time.Time{}, ErrNoSchedule
+	}
+	return t.UTC().Add(interval), nil
+}
+
+var (
+	ErrMonitorNotFound = errors.New("monitor not found")
+	ErrNoSchedule      = errors.New("monitor needs an interval or a cron expression")
+	ErrNeverRuns       = errors.New("cron expression never matches")
+	// ErrAPIKeyRequired keeps anonymous callers from registering recurring
+	// scrapes that no quota accounts for.
+	ErrAPIKeyRequired = errors.New("monitors require an api key")
+)
@@ -0,0 +1,48 @@
+package scrape
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/textdiff"
+)
+
+// Snapshot is the latest scraped state of a normalized URL, kept beyond
+// the lifetime of the job that produced it.
+type Snapshot struct {
+	URL       string       `json:"url"`
+	URLHash   string       `json:"url_hash"`
+	JobID     string       `json:"job_id"`
+	Data      *ScrapedData `json:"data"`
+	ScrapedAt time.Time    `json:"scraped_at"`
+	// APIKeyID and OptionsFingerprint scope the history: each key only
+	// sees its own scrapes, and only scrapes with the same options are
+	// compared.
+	APIKeyID           string `json:"api_key_id,omitempty"`
+	OptionsFingerprint string `json:"options_fingerprint"`
+}
+
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change describes how a URL differed between two successive scrapes.
+type Change struct {
+	URLHash            string    `json:"url_hash"`
+	OptionsFingerprint string    `json:"options_fingerprint"`
+	URL                string    `json:"url"`
+	JobID              string    `json:"job_id"`
+	PreviousJobID      string    `json:"previous_job_id"`
+	DetectedAt         time.Time `json:"detected_at"`
+	// Fields lists what changed: title, description, image_url, content.
+	Fields      []string         `json:"fields"`
+	Title       *FieldChange     `json:"title,omitempty"`
+	Description *FieldChange     `json:"description,omitempty"`
+	ImageURL    *FieldChange     `json:"image_url,omitempty"`
+	Content     *textdiff.Result `json:"content,omitempty"`
+	// Similarity of the content text, 1 when only metadata changed.
+	Similarity float64 `json:"similarity"`
+}
+
+var ErrSnapshotNotFound = errors.New("url has no snapshot")
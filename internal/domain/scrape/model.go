@@ -1,6 +1,9 @@
 package scrape
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type ScrapedData struct {
 	URL         string `json:"url"`
@@ -12,6 +15,14 @@ type ScrapedData struct {
 	ContentText string `json:"content_text"`
 	Author      string `json:"author"`
 	PublishedAt string `json:"published_at"`
+
+	HTML string `json:"html,omitempty"`
+}
+
+// CachedResult is the latest successful scrape of a normalized URL.
+type CachedResult struct {
+	Data     *ScrapedData `json:"data"`
+	CachedAt time.Time    `json:"cached_at"`
 }
 
 type JobStatus string
@@ -21,14 +32,119 @@ const (
 	StatusProcessing JobStatus = "processing"
 	StatusCompleted  JobStatus = "completed"
 	StatusFailed     JobStatus = "failed"
+	StatusCancelled  JobStatus = "cancelled"
 )
 
+var Statuses = []JobStatus{
+	StatusPending,
+	StatusProcessing,
+	StatusCompleted,
+	StatusFailed,
+	StatusCancelled,
+}
+
+// Valid reports whether s is one of the known statuses.
+func (s JobStatus) Valid() bool {
+	for _, known := range Statuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether a job in this status will not change anymore.
+func (s JobStatus) Terminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
 type Job struct {
-	ID     string      `json:"id"`
-	URL    string      `json:"url"`
-	Status JobStatus   `json:"status"`
-	Result interface{} `json:"result,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	ID       string        `json:"id"`
+	URL      string        `json:"url"`
+	Status   JobStatus     `json:"status"`
+	Result   interface{}   `json:"result,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	BatchID  string        `json:"batch_id,omitempty"`
+	Options  ScrapeOptions `json:"options"`
+	Priority Priority      `json:"priority,omitempty"`
+	// APIKeyID is the key that submitted the job, empty for anonymous calls.
+	APIKeyID string `json:"api_key_id,omitempty"`
+	// Timeline lists the engine stages of the last run.
+	Timeline []Stage `json:"timeline,omitempty"`
+	// Attempt counts runs of the job, Attempts keeps the earlier ones.
+	Attempt  int       `json:"attempt"`
+	Attempts []Attempt `json:"attempts,omitempty"`
+	// Retries counts automatic retries of transient failures in this
+	// attempt. While one is scheduled the job is pending with NextRetryAt
+	// and LastError set.
+	Retries     int        `json:"retries,omitempty"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	// Republished counts how often the reconciler re-published the job
+	// after it got stuck in pending.
+	Republished int `json:"republished,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	CachedAt  *time.Time `json:"cached_at,omitempty"`
+
+	CallbackURL    string            `json:"callback_url,omitempty"`
+	CallbackSecret string            `json:"callback_secret,omitempty"`
+	Callback       *CallbackDelivery `json:"callback,omitempty"`
 }
 
-var ErrJobNotFound = errors.New("job not found")
+// Attempt is the outcome of an earlier run of a retried job.
+type Attempt struct {
+	Attempt   int           `json:"attempt"`
+	Status    JobStatus     `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Options   ScrapeOptions `json:"options"`
+	RetriedAt time.Time     `json:"retried_at"`
+}
+
+// LastUpdate is when the job last changed, its creation for jobs stored
+// before updates were tracked.
+func (j *Job) LastUpdate() time.Time {
+	if j.UpdatedAt.IsZero() {
+		return j.CreatedAt
+	}
+	return j.UpdatedAt
+}
+
+type CallbackStatus string
+
+const (
+	CallbackDelivered CallbackStatus = "delivered"
+	CallbackFailed    CallbackStatus = "failed"
+)
+
+// CallbackDelivery records the outcome of the completion webhook for a job.
+type CallbackDelivery struct {
+	Status     CallbackStatus `json:"status"`
+	Attempts   int            `json:"attempts"`
+	StatusCode int            `json:"status_code,omitempty"`
+	LastError  string         `json:"last_error,omitempty"`
+	At         time.Time      `json:"at"`
+}
+
+// JobFilter selects jobs from the secondary indexes. Results are ordered
+// newest first and paginated with an opaque cursor. Only jobs of APIKeyID
+// match, anonymous jobs when it is empty.
+type JobFilter struct {
+	APIKeyID string
+	Status   JobStatus
+	Domain   string
+	Since    time.Time
+	Cursor   string
+	Limit    int
+}
+
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrBatchNotFound = errors.New("batch not found")
+	ErrJobCancelled  = errors.New("job cancelled")
+	ErrJobFinished   = errors.New("job already finished")
+	ErrNotRetryable  = errors.New("only failed or completed jobs can be retried")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrJobChanged    = errors.New("job changed meanwhile")
+)
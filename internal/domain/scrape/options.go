@@ -0,0 +1,113 @@
+package scrape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Strategy string
+
+const (
+	StrategyAuto    Strategy = "auto"    // static first, browser fallback
+	StrategyStatic  Strategy = "static"  // never calls the browser
+	StrategyBrowser Strategy = "browser" // skips the static scrape
+)
+
+type ScreenshotMode string
+
+const (
+	ScreenshotNever     ScreenshotMode = "never"
+	ScreenshotIfMissing ScreenshotMode = "if_missing"
+	ScreenshotAlways    ScreenshotMode = "always"
+)
+
+// MaxScrapeTimeout matches the per message deadline of the consumer.
+const MaxScrapeTimeout = time.Minute
+
+var ErrInvalidOptions = errors.New("invalid scrape options")
+
+// OptionError names the option that failed validation.
+type OptionError struct {
+	Field  string
+	Reason string
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidOptions, e.Field, e.Reason)
+}
+
+func (e *OptionError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+// ScrapeOptions tunes how a single job is scraped. The zero value keeps the
+// default static -> evaluate -> browser flow.
+type ScrapeOptions struct {
+	Strategy       Strategy       `json:"strategy,omitempty"`
+	Screenshot     ScreenshotMode `json:"screenshot,omitempty"`
+	IncludeHTML    bool           `json:"include_html,omitempty"`
+	Timeout        string         `json:"timeout,omitempty"` // e.g. "20s"
+	UserAgent      string         `json:"user_agent,omitempty"`
+	AcceptLanguage string         `json:"accept_language,omitempty"`
+}
+
+// WithDefaults fills unset fields with the default behaviour.
+func (o ScrapeOptions) WithDefaults() ScrapeOptions {
+	if o.Strategy == "" {
+		o.Strategy = StrategyAuto
+	}
+	if o.Screenshot == "" {
+		o.Screenshot = ScreenshotIfMissing
+	}
+	return o
+}
+
+// Fingerprint identifies the options that change what a scrape returns,
+// so results are only reused for requests that would get the same one.
+// Timeout and IncludeHTML are left out: neither changes the page.
+func (o ScrapeOptions) Fingerprint() string {
+	o = o.WithDefaults()
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		string(o.Strategy),
+		string(o.Screenshot),
+		o.UserAgent,
+		o.AcceptLanguage,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (o ScrapeOptions) Validate() error {
+	switch o.Strategy {
+	case "", StrategyAuto, StrategyStatic, StrategyBrowser:
+	default:
+		return &OptionError{Field: "strategy", Reason: fmt.Sprintf("%q is unknown", o.Strategy)}
+	}
+
+	switch o.Screenshot {
+	case "", ScreenshotNever, ScreenshotIfMissing, ScreenshotAlways:
+	default:
+		return &OptionError{Field: "screenshot", Reason: fmt.Sprintf("%q is unknown", o.Screenshot)}
+	}
+	// screenshots are taken by the browser, which static never calls
+	if o.Strategy == StrategyStatic && o.Screenshot == ScreenshotAlways {
+		return &OptionError{Field: "screenshot", Reason: `"always" needs the auto or browser strategy`}
+	}
+
+	if o.Timeout != "" {
+		d, err := time.ParseDuration(o.Timeout)
+		if err != nil || d <= 0 || d > MaxScrapeTimeout {
+			return &OptionError{Field: "timeout", Reason: fmt.Sprintf("must be a duration up to %s", MaxScrapeTimeout)}
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the parsed timeout, 0 when unset.
+func (o ScrapeOptions) TimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(o.Timeout)
+	return d
+}
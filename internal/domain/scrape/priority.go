@@ -0,0 +1,33 @@
+package scrape
+
+// Priority orders jobs waiting in the queue. Interactive previews should
+// use high, bulk imports low.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+// Valid reports whether p is a known priority. Empty means normal.
+func (p Priority) Valid() bool {
+	switch p {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+		return true
+	}
+	return false
+}
+
+// AMQP maps the priority to a message priority, spread out so levels can
+// be added in between without re-declaring the queue.
+func (p Priority) AMQP() uint8 {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityHigh:
+		return 9
+	default:
+		return 5
+	}
+}
@@ -0,0 +1,34 @@
+package scrape
+
+import "time"
+
+type StageName string
+
+const (
+	StageStaticFetch      StageName = "static_fetch"
+	StageParse            StageName = "parse"
+	StageEvaluate         StageName = "evaluate"
+	StageBrowser          StageName = "browser"
+	StageScreenshotUpload StageName = "screenshot_upload"
+	StageYouTube          StageName = "youtube"
+)
+
+type StageOutcome string
+
+const (
+	OutcomeOK           StageOutcome = "ok"
+	OutcomeFailed       StageOutcome = "failed"
+	OutcomeInsufficient StageOutcome = "insufficient"
+)
+
+// Stage is one step the engine took for a job, kept so a user can see why
+// a result looks the way it does.
+type Stage struct {
+	Name       StageName    `json:"name"`
+	StartedAt  time.Time    `json:"started_at"`
+	EndedAt    time.Time    `json:"ended_at"`
+	DurationMs int64        `json:"duration_ms"`
+	Outcome    StageOutcome `json:"outcome"`
+	Error      string       `json:"error,omitempty"`
+	Detail     string       `json:"detail,omitempty"`
+}
@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+const (
+	CodeInvalidBody = "invalid_body"
+	CodeValidation  = "validation_failed"
+	CodeNotFound    = "not_found"
+	CodeInternal    = "internal_error"
+)
+
+// ErrorBody is the envelope every API error is written in.
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// WriteJSON writes v as a JSON response with the given status.
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes an error envelope. code is a stable machine-readable
+// identifier, message is meant for humans.
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, ErrorBody{Error: ErrorDetail{Code: code, Message: message}})
+}
+
+// WriteFieldError writes a 400 naming the offending request field. message
+// completes the field name, e.g. "is required".
+func WriteFieldError(w http.ResponseWriter, field, message string) {
+	err := &validate.Error{Field: field, Message: message}
+	WriteJSON(w, http.StatusBadRequest, ErrorBody{Error: ErrorDetail{
+		Code:    CodeValidation,
+		Message: err.Error(),
+		Field:   field,
+	}})
+}
+
+// WriteValidationError writes err as a field error when it is a
+// validate.Error and reports whether it did.
+func WriteValidationError(w http.ResponseWriter, err error) bool {
+	var ve *validate.Error
+	if !errors.As(err, &ve) {
+		return false
+	}
+	WriteFieldError(w, ve.Field, ve.Message)
+	return true
+}
+
+// WriteInternal hides the cause of unexpected failures from clients.
+func WriteInternal(w http.ResponseWriter) {
+	WriteError(w, http.StatusInternalServerError, CodeInternal, "Internal error")
+}
+
+// DecodeJSON decodes the request body into v. An empty body is rejected
+// unless optional is set.
+func DecodeJSON(r *http.Request, v any, optional bool) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if optional && errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// ClientIP is the address the request came from. With TRUST_PROXY the
+// RealIP middleware has already replaced RemoteAddr with the forwarded one.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// WriteDecodeError reports a body that could not be decoded, naming the
+// field when the JSON had a value of the wrong type.
+func WriteDecodeError(w http.ResponseWriter, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		WriteFieldError(w, typeErr.Field, "must be a "+typeErr.Type.String())
+		return
+	}
+	WriteError(w, http.StatusBadRequest, CodeInvalidBody, "Request body must be valid JSON")
+}
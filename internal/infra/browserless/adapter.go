@@ -15,14 +15,18 @@ func NewAdapter(client *clientpkg.Client) *Adapter {
 	return &Adapter{client: client}
 }
 
-func (a *Adapter) Scrape(ctx context.Context, targetURL string) (*engine.BrowserResult, error) {
-	res, err := a.client.Scrape(ctx, targetURL)
+func (a *Adapter) Scrape(ctx context.Context, targetURL string, opts engine.BrowserOptions) (*engine.BrowserResult, error) {
+	res, err := a.client.Scrape(ctx, targetURL, clientpkg.Options{
+		Screenshot: opts.Screenshot,
+		HTML:       opts.HTML,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &engine.BrowserResult{
 		Title:       res.Title,
 		ContentText: res.ContentText,
+		HTML:        res.HTML,
 		Screenshot:  res.Screenshot,
 	}, nil
 }
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+type CreateKeyRequest struct {
+	Owner          string                 `json:"owner"`
+	DailyQuota     int                    `json:"daily_quota"`
+	MonthlyQuota   int                    `json:"monthly_quota"`
+	RatePerMinute  int                    `json:"rate_per_minute"`
+	RateBurst      int                    `json:"rate_burst"`
+	AllowedOptions *domain.AllowedOptions `json:"allowed_options,omitempty"`
+}
+
+func (r CreateKeyRequest) Validate() error {
+	if strings.TrimSpace(r.Owner) == "" {
+		return validate.Errorf("owner", "is required")
+	}
+	return nonNegative(
+		limit{"daily_quota", &r.DailyQuota},
+		limit{"monthly_quota", &r.MonthlyQuota},
+		limit{"rate_per_minute", &r.RatePerMinute},
+		limit{"rate_burst", &r.RateBurst},
+	)
+}
+
+type UpdateKeyRequest struct {
+	Owner          *string                `json:"owner,omitempty"`
+	DailyQuota     *int                   `json:"daily_quota,omitempty"`
+	MonthlyQuota   *int                   `json:"monthly_quota,omitempty"`
+	RatePerMinute  *int                   `json:"rate_per_minute,omitempty"`
+	RateBurst      *int                   `json:"rate_burst,omitempty"`
+	AllowedOptions *domain.AllowedOptions `json:"allowed_options,omitempty"`
+	Disabled       *bool                  `json:"disabled,omitempty"`
+}
+
+func (r UpdateKeyRequest) Validate() error {
+	if r.Owner != nil && strings.TrimSpace(*r.Owner) == "" {
+		return validate.Errorf("owner", "must not be empty")
+	}
+	return nonNegative(
+		limit{"daily_quota", r.DailyQuota},
+		limit{"monthly_quota", r.MonthlyQuota},
+		limit{"rate_per_minute", r.RatePerMinute},
+		limit{"rate_burst", r.RateBurst},
+	)
+}
+
+type KeyResponse struct {
+	ID             string                 `json:"id"`
+	Owner          string                 `json:"owner"`
+	DailyQuota     int                    `json:"daily_quota"`
+	MonthlyQuota   int                    `json:"monthly_quota"`
+	RatePerMinute  int                    `json:"rate_per_minute"`
+	RateBurst      int                    `json:"rate_burst"`
+	AllowedOptions *domain.AllowedOptions `json:"allowed_options,omitempty"`
+	Disabled       bool                   `json:"disabled"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Usage          *domain.Usage          `json:"usage,omitempty"`
+}
+
+// CreateKeyResponse is the only time the raw key is returned.
+type CreateKeyResponse struct {
+	KeyResponse
+	Key string `json:"key"`
+}
+
+var (
+	ErrKeyNotFound = domain.ErrKeyNotFound
+)
+
+func toKeyResponse(key *domain.APIKey) KeyResponse {
+	return KeyResponse{
+		ID:             key.ID,
+		Owner:          key.Owner,
+		DailyQuota:     key.DailyQuota,
+		MonthlyQuota:   key.MonthlyQuota,
+		RatePerMinute:  key.RatePerMinute,
+		RateBurst:      key.RateBurst,
+		AllowedOptions: key.AllowedOptions,
+		Disabled:       key.Disabled,
+		CreatedAt:      key.CreatedAt,
+	}
+}
+
+// limit is a quota or rate field, nil when absent from an update.
+type limit struct {
+	field string
+	value *int
+}
+
+func nonNegative(limits ...limit) error {
+	for _, l := range limits {
+		if l.value != nil && *l.value < 0 {
+			return validate.Errorf(l.field, "must not be negative")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+func TestKeyRequestValidate(t *testing.T) {
+	blank := " "
+	negative := -1
+
+	tests := []struct {
+		name  string
+		req   interface{ Validate() error }
+		field string
+	}{
+		{"create", CreateKeyRequest{Owner: "acme", DailyQuota: 100}, ""},
+		{"create without owner", CreateKeyRequest{Owner: " "}, "owner"},
+		{"create negative quota", CreateKeyRequest{Owner: "acme", MonthlyQuota: -1}, "monthly_quota"},
+		{"create negative burst", CreateKeyRequest{Owner: "acme", RateBurst: -1}, "rate_burst"},
+		{"empty update", UpdateKeyRequest{}, ""},
+		{"update blank owner", UpdateKeyRequest{Owner: &blank}, "owner"},
+		{"update negative rate", UpdateKeyRequest{RatePerMinute: &negative}, "rate_per_minute"},
+	}
+
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		var field string
+		if err != nil {
+			var ve *validate.Error
+			if !errors.As(err, &ve) {
+				t.Fatalf("%s: error %v is not a validation error", tt.name, err)
+			}
+			field = ve.Field
+		}
+		if field != tt.field {
+			t.Errorf("%s: error field = %q, want %q", tt.name, field, tt.field)
+		}
+	}
+}
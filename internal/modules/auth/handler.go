@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/go-chi/chi/v5"
+)
+
+type Service interface {
+	CreateKey(context.Context, CreateKeyRequest) (*CreateKeyResponse, error)
+	UpdateKey(context.Context, string, UpdateKeyRequest) (*KeyResponse, error)
+	GetKey(context.Context, string) (*KeyResponse, error)
+	ListKeys(context.Context) ([]KeyResponse, error)
+	DeleteKey(context.Context, string) error
+	Authenticate(context.Context, string) (*domain.APIKey, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateKeyRequest
+	if err := httpx.DecodeJSON(r, &req, false); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	resp, err := h.service.CreateKey(r.Context(), req)
+	if err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	var req UpdateKeyRequest
+	if err := httpx.DecodeJSON(r, &req, false); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	resp, err := h.service.UpdateKey(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetKey(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.GetKey(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		httpx.WriteInternal(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteKey(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case httpx.WriteValidationError(w, err):
+	case errors.Is(err, ErrKeyNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "key_not_found", "Key not found")
+	case errors.Is(err, ErrOwnerEmpty):
+		httpx.WriteFieldError(w, "owner", "is required")
+	case errors.Is(err, ErrBadQuota), errors.Is(err, ErrBadRate):
+		httpx.WriteError(w, http.StatusBadRequest, httpx.CodeValidation, err.Error())
+	default:
+		httpx.WriteInternal(w)
+	}
+}
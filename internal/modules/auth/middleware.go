@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKey authenticates the caller from the X-API-Key header or an
+// "Authorization: Bearer" token and stores the key in the request context.
+// Without required, requests carrying no key pass through anonymously.
+func (h *Handler) APIKey(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get(APIKeyHeader)
+			if raw == "" {
+				raw = bearerToken(r)
+			}
+			if raw == "" {
+				if required {
+					httpx.WriteError(w, http.StatusUnauthorized, "missing_api_key", "Missing API key")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := h.service.Authenticate(r.Context(), raw)
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrInvalidKey):
+					httpx.WriteError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+				case errors.Is(err, ErrKeyDisabled):
+					httpx.WriteError(w, http.StatusForbidden, "api_key_disabled", "API key disabled")
+				default:
+					httpx.WriteInternal(w)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(domain.WithAPIKey(r.Context(), key)))
+		})
+	}
+}
+
+// AdminOnly guards admin routes with a static bearer token. An empty token
+// disables the admin API entirely.
+func AdminOnly(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				httpx.WriteError(w, http.StatusNotFound, httpx.CodeNotFound, "Not found")
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(bearerToken(r)), []byte(token)) != 1 {
+				httpx.WriteError(w, http.StatusUnauthorized, "unauthorized", "Unauthorized")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
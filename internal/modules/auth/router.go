@@ -0,0 +1,13 @@
+package auth
+
+import "github.com/go-chi/chi/v5"
+
+func AdminRoutes(h *Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateKey)
+	r.Get("/", h.ListKeys)
+	r.Get("/{id}", h.GetKey)
+	r.Patch("/{id}", h.UpdateKey)
+	r.Delete("/{id}", h.DeleteKey)
+	return r
+}
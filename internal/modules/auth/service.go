@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/google/uuid"
+)
+
+const keyPrefix = "sk_"
+
+var (
+	ErrInvalidKey  = errors.New("invalid api key")
+	ErrKeyDisabled = domain.ErrKeyDisabled
+	ErrOwnerEmpty  = errors.New("owner is required")
+	ErrBadQuota    = errors.New("quotas must not be negative")
+	ErrBadRate     = errors.New("rate limits must not be negative")
+)
+
+type service struct {
+	rds *redis.Client
+}
+
+func NewService(rds *redis.Client) *service {
+	return &service{rds: rds}
+}
+
+func (s *service) CreateKey(ctx context.Context, req CreateKeyRequest) (*CreateKeyResponse, error) {
+	if req.Owner == "" {
+		return nil, ErrOwnerEmpty
+	}
+	if req.DailyQuota < 0 || req.MonthlyQuota < 0 {
+		return nil, ErrBadQuota
+	}
+	if req.RatePerMinute < 0 || req.RateBurst < 0 {
+		return nil, ErrBadRate
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	raw := keyPrefix + hex.EncodeToString(secret)
+
+	key := &domain.APIKey{
+		ID:             uuid.NewString(),
+		Hash:           hashKey(raw),
+		Owner:          req.Owner,
+		DailyQuota:     req.DailyQuota,
+		MonthlyQuota:   req.MonthlyQuota,
+		RatePerMinute:  req.RatePerMinute,
+		RateBurst:      req.RateBurst,
+		AllowedOptions: req.AllowedOptions,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.rds.SaveAPIKey(key); err != nil {
+		return nil, err
+	}
+
+	return &CreateKeyResponse{
+		KeyResponse: toKeyResponse(key),
+		Key:         raw,
+	}, nil
+}
+
+func (s *service) UpdateKey(ctx context.Context, id string, req UpdateKeyRequest) (*KeyResponse, error) {
+	key, err := s.rds.GetAPIKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Owner != nil {
+		if *req.Owner == "" {
+			return nil, ErrOwnerEmpty
+		}
+		key.Owner = *req.Owner
+	}
+	if req.DailyQuota != nil {
+		key.DailyQuota = *req.DailyQuota
+	}
+	if req.MonthlyQuota != nil {
+		key.MonthlyQuota = *req.MonthlyQuota
+	}
+	if key.DailyQuota < 0 || key.MonthlyQuota < 0 {
+		return nil, ErrBadQuota
+	}
+	if req.RatePerMinute != nil {
+		key.RatePerMinute = *req.RatePerMinute
+	}
+	if req.RateBurst != nil {
+		key.RateBurst = *req.RateBurst
+	}
+	if key.RatePerMinute < 0 || key.RateBurst < 0 {
+		return nil, ErrBadRate
+	}
+	if req.AllowedOptions != nil {
+		key.AllowedOptions = req.AllowedOptions
+	}
+	if req.Disabled != nil {
+		key.Disabled = *req.Disabled
+	}
+
+	if err := s.rds.SaveAPIKey(key); err != nil {
+		return nil, err
+	}
+	resp := toKeyResponse(key)
+	return &resp, nil
+}
+
+func (s *service) GetKey(ctx context.Context, id string) (*KeyResponse, error) {
+	key, err := s.rds.GetAPIKey(id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.rds.GetUsage(key.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toKeyResponse(key)
+	resp.Usage = usage
+	return &resp, nil
+}
+
+func (s *service) ListKeys(ctx context.Context) ([]KeyResponse, error) {
+	keys, err := s.rds.ListAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]KeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, toKeyResponse(key))
+	}
+	return resp, nil
+}
+
+func (s *service) DeleteKey(ctx context.Context, id string) error {
+	return s.rds.DeleteAPIKey(id)
+}
+
+// Authenticate resolves a raw key presented by a client.
+func (s *service) Authenticate(ctx context.Context, raw string) (*domain.APIKey, error) {
+	key, err := s.rds.GetAPIKeyByHash(hashKey(raw))
+	if err != nil {
+		if errors.Is(err, domain.ErrKeyNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+	if key.Disabled {
+		return nil, ErrKeyDisabled
+	}
+	return key, nil
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
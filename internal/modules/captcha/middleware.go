@@ -0,0 +1,52 @@
+package captcha
+
+import (
+	"net/http"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/captcha"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TokenHeader       = "X-Captcha-Token"
+	LegacyTokenHeader = "X-Turnstile-Token"
+)
+
+// Verifier checks a human-verification token issued to a browser client.
+type Verifier = domain.Verifier
+
+// Middleware requires a valid verification token from browser clients.
+// Requests authenticated with an API key are server-to-server calls and
+// skip the check. A nil verifier disables the middleware.
+func Middleware(v Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if v == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.APIKeyFromContext(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get(TokenHeader)
+			if token == "" {
+				token = r.Header.Get(LegacyTokenHeader)
+			}
+			if token == "" {
+				httpx.WriteError(w, http.StatusUnauthorized, "captcha_required", "Missing captcha token")
+				return
+			}
+
+			if err := v.Verify(r.Context(), token, httpx.ClientIP(r)); err != nil {
+				log.Warn().Err(err).Msg("Captcha verification failed")
+				httpx.WriteError(w, http.StatusUnauthorized, "captcha_invalid", "Invalid captcha token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
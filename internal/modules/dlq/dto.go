@@ -0,0 +1,53 @@
+package dlq
+
+import (
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+type DeadLetterResponse struct {
+	// JobID and URL are read from the payload, empty when it is malformed.
+	JobID    string     `json:"job_id,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	Reason   string     `json:"reason"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+	Priority uint8      `json:"priority"`
+	Payload  string     `json:"payload"`
+}
+
+type ListDeadLettersResponse struct {
+	// Total is the queue depth, Messages the oldest of them.
+	Total    int                  `json:"total"`
+	Messages []DeadLetterResponse `json:"messages"`
+}
+
+// ReplayRequest is optional, without a limit every message is replayed.
+type ReplayRequest struct {
+	Limit int `json:"limit,omitempty"`
+}
+
+func (r ReplayRequest) Validate() error {
+	if r.Limit < 0 {
+		return validate.Errorf("limit", "must not be negative")
+	}
+	return nil
+}
+
+type ReplayResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+type PurgeResponse struct {
+	Purged int `json:"purged"`
+}
+
+var (
+	ErrDisabled = mq.ErrNoDeadLetterQueue
+)
@@ -0,0 +1,83 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+type Service interface {
+	ListDeadLetters(context.Context, int) (*ListDeadLettersResponse, error)
+	Replay(context.Context, ReplayRequest) (*ReplayResponse, error)
+	Purge(context.Context) (*PurgeResponse, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			httpx.WriteValidationError(w, validate.Errorf("limit", "must be a positive integer"))
+			return
+		}
+		limit = min(n, maxListLimit)
+	}
+
+	resp, err := h.service.ListDeadLetters(r.Context(), limit)
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	var req ReplayRequest
+	if err := httpx.DecodeJSON(r, &req, true); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.Replay(r.Context(), req)
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) Purge(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Purge(r.Context())
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func writeDLQError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrDisabled) {
+		httpx.WriteError(w, http.StatusNotFound, "dead_letter_queue_disabled", "Dead-letter queue is not configured")
+		return
+	}
+	httpx.WriteInternal(w)
+}
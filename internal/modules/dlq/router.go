@@ -0,0 +1,11 @@
+package dlq
+
+import "github.com/go-chi/chi/v5"
+
+func AdminRoutes(h *Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListDeadLetters)
+	r.Post("/replay", h.Replay)
+	r.Delete("/", h.Purge)
+	return r
+}
@@ -0,0 +1,70 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+)
+
+type service struct {
+	letters *mq.DeadLetters
+}
+
+func NewService(letters *mq.DeadLetters) *service {
+	return &service{letters: letters}
+}
+
+func (s *service) ListDeadLetters(ctx context.Context, limit int) (*ListDeadLettersResponse, error) {
+	letters, total, err := s.letters.List(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListDeadLettersResponse{
+		Total:    total,
+		Messages: make([]DeadLetterResponse, 0, len(letters)),
+	}
+	for _, letter := range letters {
+		resp.Messages = append(resp.Messages, toDeadLetterResponse(letter))
+	}
+	return resp, nil
+}
+
+func (s *service) Replay(ctx context.Context, req ReplayRequest) (*ReplayResponse, error) {
+	replayed, err := s.letters.Replay(ctx, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayResponse{Replayed: replayed}, nil
+}
+
+func (s *service) Purge(ctx context.Context) (*PurgeResponse, error) {
+	purged, err := s.letters.Purge()
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeResponse{Purged: purged}, nil
+}
+
+func toDeadLetterResponse(letter mq.DeadLetter) DeadLetterResponse {
+	resp := DeadLetterResponse{
+		Reason:   letter.Reason,
+		Priority: letter.Priority,
+		Payload:  string(letter.Body),
+	}
+	if !letter.FailedAt.IsZero() {
+		resp.FailedAt = &letter.FailedAt
+	}
+
+	// only the identifying fields, the payload may be anything
+	var body struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(letter.Body, &body) == nil {
+		resp.JobID = body.ID
+		resp.URL = body.URL
+	}
+	return resp
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	scrapemod "github.com/Alkush-Pipania/Scrapper/internal/modules/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/cron"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+// MinInterval keeps monitors from hammering a site.
+const MinInterval = time.Minute
+
+type CreateMonitorRequest struct {
+	URL            string               `json:"url"`
+	Interval       string               `json:"interval,omitempty"`
+	Cron           string               `json:"cron,omitempty"`
+	Options        scrape.ScrapeOptions `json:"options"`
+	Priority       scrape.Priority      `json:"priority,omitempty"`
+	CallbackURL    string               `json:"callback_url,omitempty"`
+	CallbackSecret string               `json:"callback_secret,omitempty"`
+}
+
+func (r CreateMonitorRequest) Validate() error {
+	if err := validate.HTTPURL("url", r.URL); err != nil {
+		return err
+	}
+
+	switch {
+	case r.Interval == "" && r.Cron == "":
+		return validate.Errorf("interval", "or cron is required")
+	case r.Interval != "" && r.Cron != "":
+		return validate.Errorf("cron", "cannot be combined with interval")
+	case r.Interval != "":
+		d, err := time.ParseDuration(r.Interval)
+		if err != nil || d < MinInterval {
+			return validate.Errorf("interval", "must be a duration of at least %s", MinInterval)
+		}
+	default:
+		if _, err := cron.Parse(r.Cron); err != nil {
+			return validate.Errorf("cron", "is not a valid five-field cron expression")
+		}
+	}
+
+	if err := scrapemod.ValidateOptions(r.Options); err != nil {
+		return err
+	}
+	if !r.Priority.Valid() {
+		return validate.Errorf("priority", "must be low, normal or high")
+	}
+	if r.CallbackURL != "" {
+		if err := validate.HTTPURL("callback_url", r.CallbackURL); err != nil {
+			return err
+		}
+	} else if r.CallbackSecret != "" {
+		return validate.Errorf("callback_secret", "requires callback_url")
+	}
+	return nil
+}
+
+type MonitorResponse struct {
+	ID          string               `json:"id"`
+	URL         string               `json:"url"`
+	Interval    string               `json:"interval,omitempty"`
+	Cron        string               `json:"cron,omitempty"`
+	Options     scrape.ScrapeOptions `json:"options"`
+	Priority    scrape.Priority      `json:"priority"`
+	CallbackURL string               `json:"callback_url,omitempty"`
+	Paused      bool                 `json:"paused"`
+
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastJobID string     `json:"last_job_id,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ListMonitorsResponse struct {
+	Monitors []MonitorResponse `json:"monitors"`
+}
+
+var (
+	ErrMonitorNotFound = domain.ErrMonitorNotFound
+	ErrAPIKeyRequired  = domain.ErrAPIKeyRequired
+)
+
+func toMonitorResponse(m *domain.Monitor) MonitorResponse {
+	resp := MonitorResponse{
+		ID:          m.ID,
+		URL:         m.URL,
+		Interval:    m.Interval,
+		Cron:        m.Cron,
+		Options:     m.Options,
+		Priority:    m.Priority,
+		CallbackURL: m.CallbackURL,
+		Paused:      m.Paused,
+		LastRunAt:   m.LastRunAt,
+		LastJobID:   m.LastJobID,
+		LastError:   m.LastError,
+		CreatedAt:   m.CreatedAt,
+	}
+	// a paused monitor has no next run
+	if !m.Paused {
+		next := m.NextRunAt
+		resp.NextRunAt = &next
+	}
+	return resp
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/go-chi/chi/v5"
+)
+
+type Service interface {
+	CreateMonitor(context.Context, CreateMonitorRequest) (*MonitorResponse, error)
+	ListMonitors(context.Context) (*ListMonitorsResponse, error)
+	GetMonitor(context.Context, string) (*MonitorResponse, error)
+	PauseMonitor(context.Context, string) (*MonitorResponse, error)
+	ResumeMonitor(context.Context, string) (*MonitorResponse, error)
+	DeleteMonitor(context.Context, string) error
+}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
+	var req CreateMonitorRequest
+	if err := httpx.DecodeJSON(r, &req, false); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	resp, err := h.service.CreateMonitor(r.Context(), req)
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) ListMonitors(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ListMonitors(r.Context())
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetMonitor(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.GetMonitor(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) PauseMonitor(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.PauseMonitor(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ResumeMonitor(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ResumeMonitor(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteMonitor(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeMonitorError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMonitorError(w http.ResponseWriter, err error) {
+	switch {
+	case httpx.WriteValidationError(w, err):
+	case errors.Is(err, ErrMonitorNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "monitor_not_found", "Monitor not found")
+	case errors.Is(err, ErrAPIKeyRequired):
+		httpx.WriteError(w, http.StatusUnauthorized, "missing_api_key", "Monitors require an API key")
+	case errors.Is(err, auth.ErrOptionNotAllowed):
+		httpx.WriteError(w, http.StatusForbidden, "option_not_allowed", err.Error())
+	case errors.Is(err, domain.ErrNeverRuns):
+		httpx.WriteFieldError(w, "cron", "never matches a date")
+	default:
+		httpx.WriteInternal(w)
+	}
+}
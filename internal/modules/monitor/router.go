@@ -0,0 +1,14 @@
+package monitor
+
+import "github.com/go-chi/chi/v5"
+
+func Routes(h *Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.ListMonitors)
+	r.Post("/", h.CreateMonitor)
+	r.Get("/{id}", h.GetMonitor)
+	r.Post("/{id}/pause", h.PauseMonitor)
+	r.Post("/{id}/resume", h.ResumeMonitor)
+	r.Delete("/{id}", h.DeleteMonitor)
+	return r
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/leader"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	schedulerLock = "monitor-scheduler"
+	dueBatchSize  = 100
+)
+
+// JobSubmitter enqueues a scrape the same way the public API does.
+type JobSubmitter interface {
+	SubmitJob(context.Context, scrape.SubmitScrapeRequest) (string, error)
+}
+
+// Scheduler fires due monitors on the leader replica.
+type Scheduler struct {
+	store     *redis.Client
+	submitter JobSubmitter
+	tick      time.Duration
+}
+
+func NewScheduler(store *redis.Client, submitter JobSubmitter, tick time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		submitter: submitter,
+		tick:      tick,
+	}
+}
+
+// Run blocks until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	leader.Run(ctx, s.store, schedulerLock, s.tick, s.fireDue)
+}
+
+func (s *Scheduler) fireDue(ctx context.Context) {
+	ids, err := s.store.DueMonitors(time.Now(), dueBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load due monitors")
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		s.fire(ctx, id)
+	}
+}
+
+// fire submits one run and schedules the next. Runs missed while the
+// scheduler was down are skipped rather than replayed.
+func (s *Scheduler) fire(ctx context.Context, id string) {
+	m, err := s.store.GetMonitor(id)
+	if err != nil {
+		log.Error().Err(err).Str("monitor_id", id).Msg("Failed to load monitor")
+		return
+	}
+
+	jobID, runErr := s.submit(ctx, m)
+	if runErr != nil {
+		log.Warn().Err(runErr).Str("monitor_id", id).Msg("Monitor run failed")
+	}
+
+	now := time.Now().UTC()
+	_, err = s.store.UpdateMonitor(id, func(m *domain.Monitor) error {
+		m.LastRunAt = &now
+		if runErr != nil {
+			m.LastError = runErr.Error()
+		} else {
+			m.LastJobID = jobID
+			m.LastError = ""
+		}
+
+		next, err := m.Next(now)
+		if err != nil {
+			// nothing left to schedule, keep the monitor for inspection
+			m.Paused = true
+			m.LastError = err.Error()
+			return nil
+		}
+		m.NextRunAt = next
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Str("monitor_id", id).Msg("Failed to schedule next monitor run")
+	}
+}
+
+// submit enqueues a fresh scrape on behalf of the key that created the
+// monitor, so runs count against its quota.
+func (s *Scheduler) submit(ctx context.Context, m *domain.Monitor) (string, error) {
+	if m.APIKeyID != "" {
+		key, err := s.store.GetAPIKey(m.APIKeyID)
+		if err != nil {
+			return "", err
+		}
+		if key.Disabled {
+			return "", auth.ErrKeyDisabled
+		}
+		ctx = auth.WithAPIKey(ctx, key)
+	}
+
+	return s.submitter.SubmitJob(ctx, scrape.SubmitScrapeRequest{
+		URL:            m.URL,
+		Options:        m.Options,
+		Priority:       m.Priority,
+		CallbackURL:    m.CallbackURL,
+		CallbackSecret: m.CallbackSecret,
+		ForceRefresh:   true,
+	})
+}
@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/google/uuid"
+)
+
+type service struct {
+	rds *redis.Client
+}
+
+func NewService(rds *redis.Client) *service {
+	return &service{rds: rds}
+}
+
+func (s *service) CreateMonitor(ctx context.Context, req CreateMonitorRequest) (*MonitorResponse, error) {
+	key := auth.APIKeyFromContext(ctx)
+	if key == nil {
+		return nil, ErrAPIKeyRequired
+	}
+	if err := key.Permits(req.Options); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	m := &domain.Monitor{
+		ID:             uuid.NewString(),
+		URL:            req.URL,
+		Interval:       req.Interval,
+		Cron:           req.Cron,
+		Options:        req.Options,
+		Priority:       cmp.Or(req.Priority, scrape.PriorityLow),
+		CallbackURL:    req.CallbackURL,
+		CallbackSecret: req.CallbackSecret,
+		APIKeyID:       key.ID,
+		CreatedAt:      now,
+	}
+	next, err := firstRun(m, now)
+	if err != nil {
+		return nil, err
+	}
+	m.NextRunAt = next
+
+	if err := s.rds.SaveMonitor(m); err != nil {
+		return nil, err
+	}
+	resp := toMonitorResponse(m)
+	return &resp, nil
+}
+
+func (s *service) ListMonitors(ctx context.Context) (*ListMonitorsResponse, error) {
+	keyID := auth.KeyIDFrom(ctx)
+	if keyID == "" {
+		return nil, ErrAPIKeyRequired
+	}
+	monitors, err := s.rds.ListMonitors(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListMonitorsResponse{Monitors: make([]MonitorResponse, 0, len(monitors))}
+	for _, m := range monitors {
+		resp.Monitors = append(resp.Monitors, toMonitorResponse(m))
+	}
+	return resp, nil
+}
+
+func (s *service) GetMonitor(ctx context.Context, id string) (*MonitorResponse, error) {
+	m, err := s.ownMonitor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toMonitorResponse(m)
+	return &resp, nil
+}
+
+func (s *service) PauseMonitor(ctx context.Context, id string) (*MonitorResponse, error) {
+	keyID := auth.KeyIDFrom(ctx)
+	m, err := s.rds.UpdateMonitor(id, func(m *domain.Monitor) error {
+		if m.APIKeyID != keyID {
+			return ErrMonitorNotFound
+		}
+		m.Paused = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := toMonitorResponse(m)
+	return &resp, nil
+}
+
+// ResumeMonitor reactivates a paused monitor. Runs missed while paused are
+// skipped.
+func (s *service) ResumeMonitor(ctx context.Context, id string) (*MonitorResponse, error) {
+	keyID := auth.KeyIDFrom(ctx)
+	m, err := s.rds.UpdateMonitor(id, func(m *domain.Monitor) error {
+		if m.APIKeyID != keyID {
+			return ErrMonitorNotFound
+		}
+		if !m.Paused {
+			return nil
+		}
+		next, err := firstRun(m, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		m.Paused = false
+		m.NextRunAt = next
+		m.LastError = ""
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := toMonitorResponse(m)
+	return &resp, nil
+}
+
+func (s *service) DeleteMonitor(ctx context.Context, id string) error {
+	if _, err := s.ownMonitor(ctx, id); err != nil {
+		return err
+	}
+	return s.rds.DeleteMonitor(id)
+}
+
+// ownMonitor loads a monitor of the caller. Monitors of another key are
+// reported as not found.
+func (s *service) ownMonitor(ctx context.Context, id string) (*domain.Monitor, error) {
+	m, err := s.rds.GetMonitor(id)
+	if err != nil {
+		return nil, err
+	}
+	if m.APIKeyID != auth.KeyIDFrom(ctx) {
+		return nil, ErrMonitorNotFound
+	}
+	return m, nil
+}
+
+// firstRun schedules interval monitors right away and cron monitors at
+// their next activation.
+func firstRun(m *domain.Monitor, now time.Time) (time.Time, error) {
+	if m.Cron != "" {
+		return m.Next(now)
+	}
+	return now, nil
+}
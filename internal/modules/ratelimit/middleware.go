@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/rs/zerolog/log"
+)
+
+// Limiter takes tokens from a shared bucket.
+type Limiter interface {
+	TakeTokens(key string, n, perMinute, burst int) (*redis.RateLimitResult, error)
+}
+
+// bucket is the bucket a request was charged to, kept in its context so
+// handlers can charge the jobs it enqueues beyond the first.
+type bucket struct {
+	limiter   Limiter
+	key       string
+	perMinute int
+	burst     int
+}
+
+type bucketCtxKey struct{}
+
+// Middleware limits unsafe requests with a token bucket per API key, or per
+// client IP for anonymous callers. Reads are never limited. Buckets live in
+// Redis so the limit holds across replicas; if Redis is unavailable requests
+// are let through.
+func Middleware(limiter Limiter, cfg config.RateLimitConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, perMinute, burst := bucketFor(r, cfg)
+			if perMinute <= 0 || burst <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			b := &bucket{limiter: limiter, key: key, perMinute: perMinute, burst: burst}
+			if !b.take(w, 1) {
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), bucketCtxKey{}, b)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Charge brings the cost of a request that enqueues n jobs, such as a
+// batch, to n tokens; the middleware already took the first one. It writes
+// the 429 and returns false when the bucket cannot cover them, and always
+// allows requests the middleware did not limit.
+func Charge(w http.ResponseWriter, r *http.Request, n int) bool {
+	b, ok := r.Context().Value(bucketCtxKey{}).(*bucket)
+	if !ok || n <= 1 {
+		return true
+	}
+	// the bucket never holds that many, waiting would not help
+	if n > b.burst {
+		httpx.WriteError(w, http.StatusTooManyRequests, "rate_limited",
+			fmt.Sprintf("Request of %d jobs exceeds the rate limit burst of %d", n, b.burst))
+		return false
+	}
+	return b.take(w, n-1)
+}
+
+// take takes n tokens, sets the RateLimit headers and writes the 429 when
+// the bucket is empty. Requests are let through when Redis is unavailable.
+func (b *bucket) take(w http.ResponseWriter, n int) bool {
+	res, err := b.limiter.TakeTokens(b.key, n, b.perMinute, b.burst)
+	if err != nil {
+		log.Warn().Err(err).Str("bucket", b.key).Msg("Rate limiter unavailable, allowing request")
+		return true
+	}
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+		httpx.WriteError(w, http.StatusTooManyRequests, "rate_limited", "Rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+// bucketFor picks the bucket and its limits. Keys may carry their own
+// limits, zero values fall back to the configured defaults.
+func bucketFor(r *http.Request, cfg config.RateLimitConfig) (string, int, int) {
+	if key := auth.APIKeyFromContext(r.Context()); key != nil {
+		perMinute, burst := cfg.KeyPerMinute, cfg.KeyBurst
+		if key.RatePerMinute > 0 {
+			perMinute = key.RatePerMinute
+		}
+		if key.RateBurst > 0 {
+			burst = key.RateBurst
+		}
+		return "key:" + key.ID, perMinute, burst
+	}
+
+	return "ip:" + httpx.ClientIP(r), cfg.IPPerMinute, cfg.IPBurst
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,69 @@
+package scrape
+
+import (
+	"strings"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/textdiff"
+)
+
+// maxChangeRecords caps the change history kept per URL.
+const maxChangeRecords = 100
+
+// diffSnapshots compares two successive scrapes of a URL and returns nil
+// when nothing the caller sees has changed.
+func diffSnapshots(prev, cur *domain.Snapshot) *domain.Change {
+	if prev == nil || prev.Data == nil || cur.Data == nil {
+		return nil
+	}
+
+	change := &domain.Change{
+		URLHash:            cur.URLHash,
+		OptionsFingerprint: cur.OptionsFingerprint,
+		URL:                cur.URL,
+		JobID:              cur.JobID,
+		PreviousJobID:      prev.JobID,
+		DetectedAt:         time.Now(),
+		Similarity:         1,
+	}
+
+	if fc := diffField(prev.Data.Title, cur.Data.Title); fc != nil {
+		change.Title = fc
+		change.Fields = append(change.Fields, "title")
+	}
+	if fc := diffField(prev.Data.Description, cur.Data.Description); fc != nil {
+		change.Description = fc
+		change.Fields = append(change.Fields, "description")
+	}
+	// every screenshot is uploaded under a new name, only a change between
+	// page provided images is meaningful
+	if !isScreenshot(prev.Data.ImageURL) && !isScreenshot(cur.Data.ImageURL) {
+		if fc := diffField(prev.Data.ImageURL, cur.Data.ImageURL); fc != nil {
+			change.ImageURL = fc
+			change.Fields = append(change.Fields, "image_url")
+		}
+	}
+	if content := textdiff.Compare(prev.Data.ContentText, cur.Data.ContentText); content.Changed() {
+		change.Content = &content
+		change.Similarity = content.Similarity
+		change.Fields = append(change.Fields, "content")
+	}
+
+	if len(change.Fields) == 0 {
+		return nil
+	}
+	return change
+}
+
+func diffField(from, to string) *domain.FieldChange {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == to {
+		return nil
+	}
+	return &domain.FieldChange{From: from, To: to}
+}
+
+func isScreenshot(imageURL string) bool {
+	return strings.Contains(imageURL, "/screenshots/")
+}
@@ -1,25 +1,218 @@
 package scrape
 
-import domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/urlutil"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
 
 type SubmitScrapeRequest struct {
-	URL string `json:"url" validate:"required,url"`
+	URL            string               `json:"url"`
+	Options        domain.ScrapeOptions `json:"options"`
+	Priority       domain.Priority      `json:"priority,omitempty"`
+	CallbackURL    string               `json:"callback_url,omitempty"`
+	CallbackSecret string               `json:"callback_secret,omitempty"`
+	// Wait holds the response until the job finishes, e.g. "30s".
+	Wait string `json:"wait,omitempty"`
+	// MaxAge is the oldest cached result in seconds the caller accepts.
+	MaxAge       *int `json:"max_age,omitempty"`
+	ForceRefresh bool `json:"force_refresh,omitempty"`
+
+	// IdempotencyKey comes from the Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
+}
+
+func (r SubmitScrapeRequest) Validate() error {
+	if err := validate.HTTPURL("url", r.URL); err != nil {
+		return err
+	}
+	if err := ValidateOptions(r.Options); err != nil {
+		return err
+	}
+	if !r.Priority.Valid() {
+		return validate.Errorf("priority", "must be low, normal or high")
+	}
+	if r.CallbackURL != "" {
+		if err := validate.HTTPURL("callback_url", r.CallbackURL); err != nil {
+			return err
+		}
+	} else if r.CallbackSecret != "" {
+		return validate.Errorf("callback_secret", "requires callback_url")
+	}
+	if _, err := parseWait(r.Wait); err != nil {
+		return validate.Errorf("wait", "must be a duration such as \"30s\"")
+	}
+	if r.MaxAge != nil && *r.MaxAge < 0 {
+		return validate.Errorf("max_age", "must not be negative")
+	}
+	if len(r.IdempotencyKey) > maxIdempotencyKeyLen {
+		return validate.Errorf("Idempotency-Key", "must be at most %d characters", maxIdempotencyKeyLen)
+	}
+	return nil
+}
+
+// RetryJobRequest is optional, without options the job reruns as before.
+type RetryJobRequest struct {
+	Options *domain.ScrapeOptions `json:"options,omitempty"`
+}
+
+func (r RetryJobRequest) Validate() error {
+	if r.Options == nil {
+		return nil
+	}
+	return ValidateOptions(*r.Options)
 }
 
 type SubmitScrapeResponse struct {
 	JobID string `json:"job_id"`
 }
 
-var ErrJobNotFound = domain.ErrJobNotFound
+type SubmitBatchRequest struct {
+	Items []SubmitScrapeRequest `json:"items"`
+	// Priority applies to items that do not set their own.
+	Priority domain.Priority `json:"priority,omitempty"`
+}
+
+func (r SubmitBatchRequest) Validate() error {
+	if len(r.Items) == 0 {
+		return validate.Errorf("items", "must not be empty")
+	}
+	if len(r.Items) > maxBatchSize {
+		return validate.Errorf("items", "must have at most %d entries", maxBatchSize)
+	}
+	if !r.Priority.Valid() {
+		return validate.Errorf("priority", "must be low, normal or high")
+	}
+	for i, item := range r.Items {
+		if err := item.Validate(); err != nil {
+			return validate.Nest(fmt.Sprintf("items[%d]", i), err)
+		}
+	}
+	return nil
+}
+
+type SubmitBatchResponse struct {
+	BatchID string           `json:"batch_id"`
+	JobIDs  []string         `json:"job_ids"`
+	Failed  []BatchItemError `json:"failed,omitempty"`
+}
+
+// BatchItemError is a batch item that was stored but could not be enqueued.
+type BatchItemError struct {
+	Index int    `json:"index"`
+	JobID string `json:"job_id"`
+	Error string `json:"error"`
+}
+
+var (
+	ErrJobNotFound    = domain.ErrJobNotFound
+	ErrBatchNotFound  = domain.ErrBatchNotFound
+	ErrJobFinished    = domain.ErrJobFinished
+	ErrInvalidCursor  = domain.ErrInvalidCursor
+	ErrInvalidOptions = domain.ErrInvalidOptions
+	ErrNotRetryable   = domain.ErrNotRetryable
+
+	ErrQuotaExceeded    = auth.ErrQuotaExceeded
+	ErrOptionNotAllowed = auth.ErrOptionNotAllowed
+	ErrAPIKeyRequired   = auth.ErrAPIKeyRequired
+)
 
 type ScrapeStatusResponse struct {
-	ID     string      `json:"id"`
-	URL    string      `json:"url"`
-	Status string      `json:"status"`
-	Result interface{} `json:"result,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	ID      string `json:"id"`
+	URL     string `json:"url"`
+	URLHash string `json:"url_hash"`
+	// OptionsFingerprint selects the change history of the URL scraped
+	// with these options.
+	OptionsFingerprint string      `json:"options_fingerprint"`
+	Status             string      `json:"status"`
+	Result             interface{} `json:"result,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	BatchID            string      `json:"batch_id,omitempty"`
+
+	Priority  domain.Priority          `json:"priority"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+	CachedAt  *time.Time               `json:"cached_at,omitempty"`
+	Callback  *domain.CallbackDelivery `json:"callback,omitempty"`
+	Timeline  []domain.Stage           `json:"timeline,omitempty"`
+	Attempt   int                      `json:"attempt"`
+	Attempts  []domain.Attempt         `json:"attempts,omitempty"`
+
+	Retries     int        `json:"retries,omitempty"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Republished int        `json:"republished,omitempty"`
+}
+
+type BatchStatusResponse struct {
+	BatchID string                 `json:"batch_id"`
+	Total   int                    `json:"total"`
+	Counts  map[string]int         `json:"counts"`
+	Jobs    []ScrapeStatusResponse `json:"jobs"`
+}
+
+type ListJobsRequest struct {
+	Status string
+	Domain string
+	Since  time.Time
+	Cursor string
+	Limit  int
+}
+
+type ListJobsResponse struct {
+	Jobs       []ScrapeStatusResponse `json:"jobs"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
 }
+
 type MsgBody struct {
-	ID  string `json:"id"`
-	URL string `json:"url"`
+	ID      string               `json:"id"`
+	URL     string               `json:"url"`
+	Options domain.ScrapeOptions `json:"options"`
+	// Retry counts the automatic retries this message went through.
+	Retry int `json:"retry,omitempty"`
+}
+
+func toStatusResponse(job *domain.Job) *ScrapeStatusResponse {
+	return &ScrapeStatusResponse{
+		ID:      job.ID,
+		URL:     job.URL,
+		URLHash: urlutil.Key(job.URL),
+
+		OptionsFingerprint: job.Options.Fingerprint(),
+		Status:             string(job.Status),
+		Result:             job.Result,
+		Error:              job.Error,
+		BatchID:            job.BatchID,
+
+		Priority:  cmp.Or(job.Priority, domain.PriorityNormal),
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.LastUpdate(),
+		CachedAt:  job.CachedAt,
+		Callback:  job.Callback,
+		Timeline:  job.Timeline,
+		Attempt:   max(job.Attempt, 1),
+		Attempts:  job.Attempts,
+
+		Retries:     job.Retries,
+		NextRetryAt: job.NextRetryAt,
+		LastError:   job.LastError,
+		Republished: job.Republished,
+	}
+}
+
+// ValidateOptions reports invalid options as a field error, e.g.
+// "options.strategy". Monitors validate their options with it too.
+func ValidateOptions(opts domain.ScrapeOptions) error {
+	err := opts.Validate()
+	var optErr *domain.OptionError
+	if errors.As(err, &optErr) {
+		return &validate.Error{Field: "options." + optErr.Field, Message: optErr.Reason}
+	}
+	return err
 }
@@ -0,0 +1,106 @@
+package scrape
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+)
+
+// errField returns the field of a validation error, "" for nil.
+func errField(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var ve *validate.Error
+	if !errors.As(err, &ve) {
+		t.Fatalf("error %v is not a validation error", err)
+	}
+	return ve.Field
+}
+
+func TestSubmitScrapeRequestValidate(t *testing.T) {
+	valid := SubmitScrapeRequest{URL: "https://example.com"}
+	negative := -1
+
+	tests := []struct {
+		name  string
+		edit  func(r *SubmitScrapeRequest)
+		field string
+	}{
+		{"valid", func(r *SubmitScrapeRequest) {}, ""},
+		{"missing url", func(r *SubmitScrapeRequest) { r.URL = "" }, "url"},
+		{"ftp url", func(r *SubmitScrapeRequest) { r.URL = "ftp://example.com" }, "url"},
+		{"unknown strategy", func(r *SubmitScrapeRequest) { r.Options.Strategy = "fast" }, "options.strategy"},
+		{"static screenshot", func(r *SubmitScrapeRequest) {
+			r.Options.Strategy = domain.StrategyStatic
+			r.Options.Screenshot = domain.ScreenshotAlways
+		}, "options.screenshot"},
+		{"long timeout", func(r *SubmitScrapeRequest) { r.Options.Timeout = "2m" }, "options.timeout"},
+		{"unknown priority", func(r *SubmitScrapeRequest) { r.Priority = "urgent" }, "priority"},
+		{"callback", func(r *SubmitScrapeRequest) {
+			r.CallbackURL = "https://hooks.example.com"
+			r.CallbackSecret = "s3cret"
+		}, ""},
+		{"invalid callback", func(r *SubmitScrapeRequest) { r.CallbackURL = "hooks" }, "callback_url"},
+		{"secret without callback", func(r *SubmitScrapeRequest) { r.CallbackSecret = "s3cret" }, "callback_secret"},
+		{"wait", func(r *SubmitScrapeRequest) { r.Wait = "30s" }, ""},
+		{"invalid wait", func(r *SubmitScrapeRequest) { r.Wait = "soon" }, "wait"},
+		{"negative wait", func(r *SubmitScrapeRequest) { r.Wait = "-1s" }, "wait"},
+		{"negative max age", func(r *SubmitScrapeRequest) { r.MaxAge = &negative }, "max_age"},
+		{"long idempotency key", func(r *SubmitScrapeRequest) {
+			r.IdempotencyKey = strings.Repeat("k", maxIdempotencyKeyLen+1)
+		}, "Idempotency-Key"},
+	}
+
+	for _, tt := range tests {
+		req := valid
+		tt.edit(&req)
+		if got := errField(t, req.Validate()); got != tt.field {
+			t.Errorf("%s: error field = %q, want %q", tt.name, got, tt.field)
+		}
+	}
+}
+
+func TestSubmitBatchRequestValidate(t *testing.T) {
+	item := SubmitScrapeRequest{URL: "https://example.com"}
+
+	tests := []struct {
+		name  string
+		req   SubmitBatchRequest
+		field string
+	}{
+		{"valid", SubmitBatchRequest{Items: []SubmitScrapeRequest{item, item}}, ""},
+		{"empty", SubmitBatchRequest{}, "items"},
+		{"too large", SubmitBatchRequest{Items: make([]SubmitScrapeRequest, maxBatchSize+1)}, "items"},
+		{"unknown priority", SubmitBatchRequest{Items: []SubmitScrapeRequest{item}, Priority: "urgent"}, "priority"},
+		{"invalid item", SubmitBatchRequest{Items: []SubmitScrapeRequest{item, {URL: "example.com"}}}, "items[1].url"},
+	}
+
+	for _, tt := range tests {
+		if got := errField(t, tt.req.Validate()); got != tt.field {
+			t.Errorf("%s: error field = %q, want %q", tt.name, got, tt.field)
+		}
+	}
+}
+
+func TestRetryJobRequestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   RetryJobRequest
+		field string
+	}{
+		{"no options", RetryJobRequest{}, ""},
+		{"valid options", RetryJobRequest{Options: &domain.ScrapeOptions{Strategy: domain.StrategyBrowser}}, ""},
+		{"invalid options", RetryJobRequest{Options: &domain.ScrapeOptions{Screenshot: "sometimes"}}, "options.screenshot"},
+	}
+
+	for _, tt := range tests {
+		if got := errField(t, tt.req.Validate()); got != tt.field {
+			t.Errorf("%s: error field = %q, want %q", tt.name, got, tt.field)
+		}
+	}
+}
@@ -9,8 +9,12 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (s *Scraper) scrapeViaBrowser(ctx context.Context, targetURL string) (*domain.ScrapedData, error) {
-	res, err := s.browser.Scrape(ctx, targetURL)
+// scrapeViaBrowser renders targetURL in the browser. purpose is recorded on
+// the timeline to tell why the browser was used.
+func (s *Scraper) scrapeViaBrowser(ctx context.Context, targetURL string, opts BrowserOptions, tl *timeline, purpose string) (*domain.ScrapedData, error) {
+	start := time.Now()
+	res, err := s.browser.Scrape(ctx, targetURL, opts)
+	tl.record(domain.StageBrowser, start, domain.OutcomeOK, err, purpose)
 	if err != nil {
 		return nil, err
 	}
@@ -19,13 +23,16 @@ func (s *Scraper) scrapeViaBrowser(ctx context.Context, targetURL string) (*doma
 		URL:         targetURL,
 		Title:       res.Title,
 		ContentText: res.ContentText,
+		HTML:        capHTML(res.HTML),
 		SiteName:    "Web",
 	}
 
 	// Handle Screenshot Upload via Interface
 	if len(res.Screenshot) > 0 {
+		uploadStart := time.Now()
 		fileName := fmt.Sprintf("screenshots/%d.jpg", time.Now().UnixNano())
 		imgURL, err := s.uploader.Upload(ctx, fileName, res.Screenshot, "image/jpeg")
+		tl.record(domain.StageScreenshotUpload, uploadStart, domain.OutcomeOK, err, fileName)
 		if err == nil {
 			data.ImageURL = imgURL
 		} else {
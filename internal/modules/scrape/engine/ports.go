@@ -5,11 +5,17 @@ import "context"
 type BrowserResult struct {
 	Title       string
 	ContentText string
+	HTML        string
 	Screenshot  []byte
 }
 
+type BrowserOptions struct {
+	Screenshot bool
+	HTML       bool
+}
+
 type Browser interface {
-	Scrape(ctx context.Context, targetURL string) (*BrowserResult, error)
+	Scrape(ctx context.Context, targetURL string, opts BrowserOptions) (*BrowserResult, error)
 }
 
 type Uploader interface {
@@ -2,14 +2,18 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
 	"github.com/rs/zerolog/log"
 )
 
+var ErrNoBrowser = errors.New("browser strategy requested but no browser is configured")
+
 type Scraper struct {
 	browser  Browser
 	uploader Uploader
@@ -26,22 +30,60 @@ func New(b Browser, u Uploader, yt YouTube, h *http.Client) *Scraper {
 	}
 }
 
-func (s *Scraper) Scrape(ctx context.Context, targetURL string) (*domain.ScrapedData, error) {
+// Scrape extracts a preview of targetURL. Alongside the result it returns
+// the stages it went through, also when the scrape fails.
+func (s *Scraper) Scrape(ctx context.Context, targetURL string, opts domain.ScrapeOptions) (*domain.ScrapedData, []domain.Stage, error) {
+	opts = opts.WithDefaults()
+	if timeout := opts.TimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tl := &timeline{}
+	data, err := s.scrape(ctx, targetURL, opts, tl)
+	return data, tl.list(), err
+}
+
+func (s *Scraper) scrape(ctx context.Context, targetURL string, opts domain.ScrapeOptions, tl *timeline) (*domain.ScrapedData, error) {
 	// YouTube short-circuit
 	if s.youtube != nil && s.youtube.IsYouTubeURL(targetURL) {
-		return s.scrapeYouTube(ctx, targetURL)
+		return s.scrapeYouTube(ctx, targetURL, tl)
+	}
+
+	browserOpts := BrowserOptions{
+		Screenshot: opts.Screenshot != domain.ScreenshotNever,
+		HTML:       opts.IncludeHTML,
+	}
+
+	// Caller knows the page needs JS, go straight to the browser
+	if opts.Strategy == domain.StrategyBrowser {
+		if s.browser == nil {
+			return nil, ErrNoBrowser
+		}
+		return s.scrapeViaBrowser(ctx, targetURL, browserOpts, tl, "browser strategy")
 	}
 
+	useBrowser := s.browser != nil && opts.Strategy != domain.StrategyStatic
+
 	// 1) Static scrape first
-	staticData, staticErr := s.scrapeStatic(ctx, targetURL)
+	staticData, staticErr := s.scrapeStatic(ctx, targetURL, opts, tl)
 	var eval staticEval
 
 	if staticErr == nil { // TODO : refactor the nesting
+		evalStart := time.Now()
 		eval = s.evaluateStatic(staticData)
+		outcome := domain.OutcomeOK
+		if !eval.ok {
+			outcome = domain.OutcomeInsufficient
+		}
+		tl.record(domain.StageEvaluate, evalStart, outcome, nil, eval.reason)
+
 		if eval.ok {
-			// Try to fill missing image with browser screenshot (only if needed)
-			if eval.needsScreenshot && s.browser != nil {
-				if bData, bErr := s.scrapeViaBrowser(ctx, targetURL); bErr == nil && bData.ImageURL != "" {
+			// Try to fill the image with a browser screenshot (only if wanted)
+			if useBrowser && wantsScreenshot(opts.Screenshot, eval) {
+				shotOpts := BrowserOptions{Screenshot: true}
+				if bData, bErr := s.scrapeViaBrowser(ctx, targetURL, shotOpts, tl, "screenshot for missing image"); bErr == nil && bData.ImageURL != "" {
 					staticData.ImageURL = bData.ImageURL
 				} else if bErr != nil {
 					log.Warn().Err(bErr).Str("url", targetURL).Msg("Browser screenshot failed")
@@ -56,16 +98,22 @@ func (s *Scraper) Scrape(ctx context.Context, targetURL string) (*domain.Scraped
 	}
 
 	// 2) Fallback to browserless
-	if s.browser != nil {
-		bData, bErr := s.scrapeViaBrowser(ctx, targetURL)
+	var browserErr error
+	if useBrowser {
+		bData, bErr := s.scrapeViaBrowser(ctx, targetURL, browserOpts, tl, "fallback after static scrape")
 		if bErr == nil {
 			return bData, nil
 		}
+		browserErr = bErr
 		log.Warn().Err(bErr).Str("url", targetURL).Msg("Browser scraping failed")
 	}
 
-	// 3) If browser also failed, return best available result
+	// 3) If browser also failed, return best available result. Both errors
+	// are kept so callers can tell whether a retry may help.
 	if staticErr != nil {
+		if browserErr != nil {
+			return nil, fmt.Errorf("%w; browser: %w", staticErr, browserErr)
+		}
 		return nil, staticErr
 	}
 
@@ -79,5 +127,19 @@ func (s *Scraper) Scrape(ctx context.Context, targetURL string) (*domain.Scraped
 	if eval.reason == "" {
 		eval.reason = "no usable content"
 	}
+	if browserErr != nil {
+		return nil, fmt.Errorf("static scrape insufficient: %s; browser: %w", eval.reason, browserErr)
+	}
 	return nil, fmt.Errorf("static scrape insufficient: %s", eval.reason)
 }
+
+func wantsScreenshot(mode domain.ScreenshotMode, eval staticEval) bool {
+	switch mode {
+	case domain.ScreenshotAlways:
+		return true
+	case domain.ScreenshotIfMissing:
+		return eval.needsScreenshot
+	default:
+		return false
+	}
+}
@@ -7,20 +7,52 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-shiori/go-readability"
 )
 
-func (s *Scraper) scrapeStatic(ctx context.Context, targetURL string) (*domain.ScrapedData, error) {
-	htmlBytes, err := s.fetchHTML(ctx, targetURL)
+// maxHTMLSize caps the raw HTML returned with include_html.
+const maxHTMLSize = 2 * 1024 * 1024
+
+// capHTML truncates raw HTML to maxHTMLSize.
+func capHTML(html string) string {
+	return html[:min(len(html), maxHTMLSize)]
+}
+
+// StatusError is returned when the target page answers with an HTTP error.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch (status %d): %s", e.StatusCode, e.URL)
+}
+
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+func (s *Scraper) scrapeStatic(ctx context.Context, targetURL string, opts domain.ScrapeOptions, tl *timeline) (*domain.ScrapedData, error) {
+	fetchStart := time.Now()
+	htmlBytes, err := s.fetchHTML(ctx, targetURL, opts)
 	if err != nil {
+		tl.record(domain.StageStaticFetch, fetchStart, domain.OutcomeFailed, err, "")
 		return nil, err
 	}
+	tl.record(domain.StageStaticFetch, fetchStart, domain.OutcomeOK, nil, fmt.Sprintf("%d bytes", len(htmlBytes)))
+
+	parseStart := time.Now()
 
 	result := &domain.ScrapedData{URL: targetURL}
+	if opts.IncludeHTML {
+		result.HTML = capHTML(string(htmlBytes))
+	}
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	var readabilityErr, metaErr error
@@ -85,22 +117,36 @@ func (s *Scraper) scrapeStatic(ctx context.Context, targetURL string) (*domain.S
 	select {
 	case <-done:
 	case <-ctx.Done():
+		tl.record(domain.StageParse, parseStart, domain.OutcomeFailed, ctx.Err(), "")
 		return nil, ctx.Err()
 	}
 
 	// Fail only if both parsers failed
 	if readabilityErr != nil && metaErr != nil {
-		return nil, fmt.Errorf("both parsers failed: readability=%v, meta=%v", readabilityErr, metaErr)
+		err := fmt.Errorf("both parsers failed: readability=%v, meta=%v", readabilityErr, metaErr)
+		tl.record(domain.StageParse, parseStart, domain.OutcomeFailed, err, "")
+		return nil, err
+	}
+
+	var notes []string
+	if readabilityErr != nil {
+		notes = append(notes, "readability failed: "+readabilityErr.Error())
+	}
+	if metaErr != nil {
+		notes = append(notes, "meta parse failed: "+metaErr.Error())
 	}
 
 	if result.ContentText == "" && result.Title == "" {
-		return result, fmt.Errorf("no meaningful content extracted from %s", targetURL)
+		err := fmt.Errorf("no meaningful content extracted from %s", targetURL)
+		tl.record(domain.StageParse, parseStart, domain.OutcomeInsufficient, err, strings.Join(notes, "; "))
+		return result, err
 	}
 
+	tl.record(domain.StageParse, parseStart, domain.OutcomeOK, nil, strings.Join(notes, "; "))
 	return result, nil
 }
 
-func (s *Scraper) fetchHTML(ctx context.Context, urlStr string) ([]byte, error) {
+func (s *Scraper) fetchHTML(ctx context.Context, urlStr string, opts domain.ScrapeOptions) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
@@ -111,6 +157,12 @@ func (s *Scraper) fetchHTML(ctx context.Context, urlStr string) ([]byte, error)
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	req.Header.Set("Cache-Control", "no-cache")
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	if opts.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", opts.AcceptLanguage)
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -119,7 +171,7 @@ func (s *Scraper) fetchHTML(ctx context.Context, urlStr string) ([]byte, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("failed to fetch (status %d): %s", resp.StatusCode, urlStr)
+		return nil, &StatusError{URL: urlStr, StatusCode: resp.StatusCode}
 	}
 	limitedBody := io.LimitReader(resp.Body, 50*1024*1024)
 	return io.ReadAll(limitedBody)
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+// timeline collects the stages of a single scrape.
+type timeline struct {
+	mu     sync.Mutex
+	stages []domain.Stage
+}
+
+// record appends a stage that started at start and ends now. A non-nil err
+// marks the stage failed regardless of outcome.
+func (t *timeline) record(name domain.StageName, start time.Time, outcome domain.StageOutcome, err error, detail string) {
+	end := time.Now().UTC()
+	stage := domain.Stage{
+		Name:       name,
+		StartedAt:  start.UTC(),
+		EndedAt:    end,
+		DurationMs: end.Sub(start).Milliseconds(),
+		Outcome:    outcome,
+		Detail:     detail,
+	}
+	if err != nil {
+		stage.Outcome = domain.OutcomeFailed
+		stage.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	t.stages = append(t.stages, stage)
+	t.mu.Unlock()
+}
+
+func (t *timeline) list() []domain.Stage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]domain.Stage(nil), t.stages...)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
 )
 
-func (s *Scraper) scrapeYouTube(ctx context.Context, targetURL string) (*domain.ScrapedData, error) {
+func (s *Scraper) scrapeYouTube(ctx context.Context, targetURL string, tl *timeline) (*domain.ScrapedData, error) {
+	start := time.Now()
 	video, err := s.youtube.GetVideoData(ctx, targetURL)
+	tl.record(domain.StageYouTube, start, domain.OutcomeOK, err, "")
 	if err != nil {
 		return nil, fmt.Errorf("youtube scrape failed: %w", err)
 	}
@@ -3,55 +3,110 @@ package scrape
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/Alkush-Pipania/Scrapper/pkg/turnstile"
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/ratelimit"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
 	"github.com/go-chi/chi/v5"
 )
 
+const (
+	sseHeartbeat = 15 * time.Second
+	maxWait      = 60 * time.Second
+
+	defaultListLimit = 50
+	maxListLimit     = 200
+
+	maxIdempotencyKeyLen = 255
+)
+
 type Service interface {
 	SubmitJob(context.Context, SubmitScrapeRequest) (string, error)
+	SubmitBatch(context.Context, SubmitBatchRequest) (*SubmitBatchResponse, error)
 	GetJobStatus(context.Context, string) (*ScrapeStatusResponse, error)
+	GetBatchStatus(context.Context, string) (*BatchStatusResponse, error)
+	WatchJob(context.Context, string) (<-chan *ScrapeStatusResponse, error)
+	WaitForJob(context.Context, string, time.Duration) (*ScrapeStatusResponse, error)
+	CancelJob(context.Context, string) (*ScrapeStatusResponse, error)
+	RetryJob(context.Context, string, RetryJobRequest) (*ScrapeStatusResponse, error)
+	ListJobs(context.Context, ListJobsRequest) (*ListJobsResponse, error)
 }
 
 type Handler struct {
-	service   Service
-	turnstile *turnstile.Client
+	service Service
 }
 
-func NewHandler(service Service, turnstile *turnstile.Client) *Handler {
+func NewHandler(service Service) *Handler {
 	return &Handler{
-		service:   service,
-		turnstile: turnstile,
+		service: service,
 	}
 }
 
 func (h *Handler) SubmitScrape(w http.ResponseWriter, r *http.Request) {
+	var req SubmitScrapeRequest
+	if err := httpx.DecodeJSON(r, &req, false); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if q := r.URL.Query().Get("wait"); q != "" {
+		req.Wait = q
+	}
+	req.IdempotencyKey = r.Header.Get("Idempotency-Key")
+	if err := req.Validate(); err != nil {
+		writeSubmitError(w, err)
+		return
+	}
+	wait, _ := parseWait(req.Wait)
 
-	// token := r.Header.Get("X-Turnstile-Token")
-	// if token == "" {
-	// 	http.Error(w, "Missing turnstile token", http.StatusUnauthorized)
-	// 	return
-	// }
+	jobID, err := h.service.SubmitJob(r.Context(), req)
+	if err != nil {
+		writeSubmitError(w, err)
+		return
+	}
 
-	// if err := h.turnstile.Verify(r.Context(), token, r.RemoteAddr); err != nil {
-	// 	http.Error(w, "Invalid turnstile token", http.StatusUnauthorized)
-	// 	return
-	// }
+	if wait > 0 {
+		// leave room to write the result after the wait expires
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 5*time.Second))
 
-	var req SubmitScrapeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid body", http.StatusBadRequest)
+		resp, err := h.service.WaitForJob(r.Context(), jobID, wait)
+		if err == nil && resp != nil && domain.JobStatus(resp.Status).Terminal() {
+			httpx.WriteJSON(w, http.StatusOK, resp)
+			return
+		}
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, SubmitScrapeResponse{JobID: jobID})
+}
+
+func (h *Handler) SubmitBatch(w http.ResponseWriter, r *http.Request) {
+	var req SubmitBatchRequest
+	if err := httpx.DecodeJSON(r, &req, false); err != nil {
+		httpx.WriteDecodeError(w, err)
 		return
 	}
-	jobID, err := h.service.SubmitJob(r.Context(), req)
+	if err := req.Validate(); err != nil {
+		writeSubmitError(w, err)
+		return
+	}
+	// every item enqueues a job, charge them all against the rate limit
+	if !ratelimit.Charge(w, r, len(req.Items)) {
+		return
+	}
+
+	resp, err := h.service.SubmitBatch(r.Context(), req)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeSubmitError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(SubmitScrapeResponse{JobID: jobID})
+	httpx.WriteJSON(w, http.StatusAccepted, resp)
 }
 
 func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
@@ -59,13 +114,225 @@ func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.service.GetJobStatus(r.Context(), jobID)
 	if err != nil {
-		if err == ErrJobNotFound {
-			http.Error(w, "Job not found", http.StatusNotFound)
+		writeJobError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	resp, err := h.service.CancelJob(r.Context(), jobID)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	// the body is optional, an empty one retries with the same options
+	var req RetryJobRequest
+	if err := httpx.DecodeJSON(r, &req, true); err != nil {
+		httpx.WriteDecodeError(w, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeSubmitError(w, err)
+		return
+	}
+
+	resp, err := h.service.RetryJob(r.Context(), jobID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrJobNotFound), errors.Is(err, ErrNotRetryable):
+			writeJobError(w, err)
+		default:
+			writeSubmitError(w, err)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, resp)
+}
+
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListJobs(r)
+	if err != nil {
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.ListJobs(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCursor):
+			httpx.WriteFieldError(w, "cursor", "is invalid or expired")
+		case errors.Is(err, ErrAPIKeyRequired):
+			httpx.WriteError(w, http.StatusUnauthorized, "missing_api_key", "Listing jobs requires an API key")
+		default:
+			httpx.WriteInternal(w)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetBatchStatus(w http.ResponseWriter, r *http.Request) {
+	batchID := chi.URLParam(r, "id")
+
+	resp, err := h.service.GetBatchStatus(r.Context(), batchID)
+	if err != nil {
+		if errors.Is(err, ErrBatchNotFound) {
+			httpx.WriteError(w, http.StatusNotFound, "batch_not_found", "Batch not found")
 			return
 		}
-		http.Error(w, "Internal error", http.StatusInternalServerError)
+		httpx.WriteInternal(w)
 		return
 	}
 
-	json.NewEncoder(w).Encode(resp)
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// StreamEvents pushes job status transitions as Server-Sent Events until the
+// job reaches a terminal status or the client goes away.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	rc := http.NewResponseController(w)
+	// the server wide WriteTimeout would cut long-lived streams
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		httpx.WriteError(w, http.StatusInternalServerError, "streaming_unsupported", "Streaming unsupported")
+		return
+	}
+
+	events, err := h.service.WatchJob(r.Context(), jobID)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Status, data); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		}
+	}
+}
+
+// writeSubmitError maps errors from the submission paths. Validation
+// errors name the offending field.
+func writeSubmitError(w http.ResponseWriter, err error) {
+	var quotaErr *auth.QuotaError
+	switch {
+	case httpx.WriteValidationError(w, err):
+	case errors.As(err, &quotaErr):
+		retryAfter := int(time.Until(quotaErr.ResetAt).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		httpx.WriteError(w, http.StatusTooManyRequests, "quota_exceeded", quotaErr.Error())
+	case errors.Is(err, ErrOptionNotAllowed):
+		httpx.WriteError(w, http.StatusForbidden, "option_not_allowed", err.Error())
+	case errors.Is(err, ErrEmptyBatch), errors.Is(err, ErrBatchTooLarge), errors.Is(err, ErrInvalidOptions):
+		httpx.WriteError(w, http.StatusBadRequest, httpx.CodeValidation, err.Error())
+	case errors.Is(err, ErrEnqueueFailed):
+		httpx.WriteError(w, http.StatusServiceUnavailable, "queue_unavailable", "Job could not be enqueued, try again")
+	default:
+		httpx.WriteInternal(w)
+	}
+}
+
+// writeJobError maps errors from lookups and transitions of a single job.
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		httpx.WriteError(w, http.StatusNotFound, "job_not_found", "Job not found")
+	case errors.Is(err, ErrJobFinished):
+		httpx.WriteError(w, http.StatusConflict, "job_finished", "Job already finished")
+	case errors.Is(err, ErrNotRetryable):
+		httpx.WriteError(w, http.StatusConflict, "job_not_retryable", err.Error())
+	default:
+		httpx.WriteInternal(w)
+	}
+}
+
+func parseWait(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("invalid wait %q", raw)
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait, nil
+}
+
+// parseListJobs reads the list filters from the query string. since accepts
+// an RFC 3339 timestamp or a duration relative to now, e.g. "1h".
+func parseListJobs(r *http.Request) (ListJobsRequest, error) {
+	q := r.URL.Query()
+	req := ListJobsRequest{
+		Status: q.Get("status"),
+		Domain: q.Get("domain"),
+		Cursor: q.Get("cursor"),
+		Limit:  defaultListLimit,
+	}
+
+	if req.Status != "" && !domain.JobStatus(req.Status).Valid() {
+		return req, validate.Errorf("status", "must be one of %v", domain.Statuses)
+	}
+
+	if since := q.Get("since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			req.Since = t
+		} else if d, err := time.ParseDuration(since); err == nil && d > 0 {
+			req.Since = time.Now().Add(-d)
+		} else {
+			return req, validate.Errorf("since", "must be an RFC 3339 timestamp or a duration such as \"1h\"")
+		}
+	}
+
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return req, validate.Errorf("limit", "must be a positive integer")
+		}
+		req.Limit = min(n, maxListLimit)
+	}
+	return req, nil
 }
@@ -0,0 +1,48 @@
+package scrape
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseListJobs(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+		limit int
+		since bool
+	}{
+		{"", "", defaultListLimit, false},
+		{"status=failed&domain=example.com&cursor=1-abc", "", defaultListLimit, false},
+		{"status=broken", "status", 0, false},
+		{"since=2026-10-01T00:00:00Z", "", defaultListLimit, true},
+		{"since=1h", "", defaultListLimit, true},
+		{"since=-1h", "since", 0, false},
+		{"since=yesterday", "since", 0, false},
+		{"limit=10", "", 10, false},
+		{"limit=1000", "", maxListLimit, false},
+		{"limit=0", "limit", 0, false},
+		{"limit=ten", "limit", 0, false},
+	}
+
+	for _, tt := range tests {
+		req, err := parseListJobs(httptest.NewRequest("GET", "/v1/jobs?"+tt.query, nil))
+		if got := errField(t, err); got != tt.field {
+			t.Errorf("%q: error field = %q, want %q", tt.query, got, tt.field)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if req.Limit != tt.limit {
+			t.Errorf("%q: limit = %d, want %d", tt.query, req.Limit, tt.limit)
+		}
+		if got := !req.Since.IsZero(); got != tt.since {
+			t.Errorf("%q: since set = %v, want %v", tt.query, got, tt.since)
+		}
+		if tt.since && req.Since.After(time.Now()) {
+			t.Errorf("%q: since %v is in the future", tt.query, req.Since)
+		}
+	}
+}
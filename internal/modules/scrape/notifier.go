@@ -0,0 +1,109 @@
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/Alkush-Pipania/Scrapper/pkg/webhook"
+	"github.com/rs/zerolog/log"
+)
+
+// deliveryTimeout bounds a whole delivery including retries.
+const deliveryTimeout = 5 * time.Minute
+
+// Notifier POSTs the final job status to the job's callback URL.
+type Notifier struct {
+	store  *redis.Client
+	client *webhook.Client
+	secret string
+	wg     sync.WaitGroup
+}
+
+func NewNotifier(store *redis.Client, client *webhook.Client, defaultSecret string) *Notifier {
+	return &Notifier{
+		store:  store,
+		client: client,
+		secret: defaultSecret,
+	}
+}
+
+// Notify delivers the callback in the background so the worker slot is
+// released while retries are pending.
+func (n *Notifier) Notify(jobID string) {
+	job, err := n.store.GetJob(jobID)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", jobID).Msg("Failed to load job for callback")
+		return
+	}
+	if job.CallbackURL == "" {
+		return
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.deliver(job)
+	}()
+}
+
+func (n *Notifier) deliver(job *domain.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	payload, err := json.Marshal(toStatusResponse(job))
+	if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to encode callback payload")
+		return
+	}
+
+	secret := job.CallbackSecret
+	if secret == "" {
+		secret = n.secret
+	}
+
+	res := n.client.Deliver(ctx, job.CallbackURL, secret, "job."+string(job.Status), payload)
+
+	delivery := &domain.CallbackDelivery{
+		Status:     domain.CallbackDelivered,
+		Attempts:   res.Attempts,
+		StatusCode: res.StatusCode,
+		At:         time.Now().UTC(),
+	}
+	if res.Err != nil {
+		delivery.Status = domain.CallbackFailed
+		delivery.LastError = res.Err.Error()
+		log.Warn().
+			Err(res.Err).
+			Str("job_id", job.ID).
+			Int("attempts", res.Attempts).
+			Msg("Callback delivery failed")
+	}
+
+	err = n.store.UpdateCallback(job.ID, job.Attempt, delivery)
+	if errors.Is(err, domain.ErrJobChanged) {
+		log.Info().Str("job_id", job.ID).Msg("Job changed during callback delivery, outcome not recorded")
+	} else if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to record callback delivery")
+	}
+}
+
+// Wait blocks until in-flight deliveries finish or ctx is done.
+func (n *Notifier) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,70 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+	"github.com/Alkush-Pipania/Scrapper/pkg/urlutil"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// hostSlotLease bounds how long a slot stays taken when its worker dies
+	// before releasing it.
+	hostSlotLease = 2 * time.Minute
+	// hostBusyDelay is how long a job waits when every slot of its host is
+	// taken, scrapes holding them usually take a few seconds.
+	hostBusyDelay = 2 * time.Second
+)
+
+// errHostBusy makes the consumer requeue a job that could neither get a
+// slot on its host nor be deferred.
+var errHostBusy = fmt.Errorf("host busy: %w", mq.ErrRequeue)
+
+// acquireHost takes a slot on the host of rawURL under the politeness
+// limits without waiting. It returns the function that frees the slot
+// again, or nil and how long to wait before trying again.
+func (w *ScrapeWorker) acquireHost(jobID, rawURL string) (func(), time.Duration) {
+	host := urlutil.Host(rawURL)
+	limit := w.cfg.Politeness.For(host)
+	if host == "" || (limit.Concurrency <= 0 && limit.Delay <= 0) {
+		return func() {}, 0
+	}
+
+	ok, wait, err := w.store.AcquireHostSlot(host, jobID, limit.Concurrency, limit.Delay, hostSlotLease)
+	if err != nil {
+		// fail open, politeness must not stop scraping
+		log.Warn().Err(err).Str("host", host).Msg("Failed to acquire host slot")
+		return func() {}, 0
+	}
+	if !ok {
+		if wait <= 0 {
+			wait = hostBusyDelay
+		}
+		return nil, wait
+	}
+	return func() {
+		if err := w.store.ReleaseHostSlot(host, jobID); err != nil {
+			log.Warn().Err(err).Str("host", host).Msg("Failed to release host slot")
+		}
+	}, 0
+}
+
+// deferJob parks the message in the defer queue for wait, from where it
+// comes back to the main queue, so no worker is held while the host is
+// busy. The job keeps its retry count. Jitter spreads out jobs of the
+// same host that would otherwise all come back at once.
+func (w *ScrapeWorker) deferJob(ctx context.Context, body []byte, priority uint8, wait time.Duration) error {
+	if w.cfg.DeferQueue == "" {
+		return errors.New("no defer queue")
+	}
+	wait += rand.N(wait/2 + 1)
+
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return w.publisher.PublishDelayed(pubCtx, w.cfg.DeferQueue, body, priority, wait)
+}
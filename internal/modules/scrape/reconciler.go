@@ -0,0 +1,168 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/leader"
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	reconcilerLock = "job-reconciler"
+	reconcileBatch = 100
+	// maxRepublish bounds the re-publishes of a job that keeps getting
+	// stuck in pending before it is failed.
+	maxRepublish = 3
+)
+
+// Reconciler repairs jobs Redis and RabbitMQ disagree about. Pending jobs
+// whose message was lost, e.g. because the API died between storing and
+// publishing, are published again; processing jobs whose worker died are
+// failed. Only the leader replica acts.
+type Reconciler struct {
+	store     *redis.Client
+	publisher *mq.Publisher
+	notifier  *Notifier
+	conn      *mq.Connection
+	queues    []string // where pending jobs wait for a worker
+	cfg       config.ReconcilerConfig
+}
+
+func NewReconciler(store *redis.Client, publisher *mq.Publisher, notifier *Notifier, conn *mq.Connection, queues []string, cfg config.ReconcilerConfig) *Reconciler {
+	return &Reconciler{
+		store:     store,
+		publisher: publisher,
+		notifier:  notifier,
+		conn:      conn,
+		queues:    queues,
+		cfg:       cfg,
+	}
+}
+
+// Run blocks until ctx is done.
+func (r *Reconciler) Run(ctx context.Context) {
+	if r.cfg.Interval <= 0 {
+		return
+	}
+
+	leader.Run(ctx, r.store, reconcilerLock, r.cfg.Interval, func(ctx context.Context) {
+		r.reconcilePending(ctx)
+		r.reconcileProcessing(ctx)
+	})
+}
+
+func (r *Reconciler) reconcilePending(ctx context.Context) {
+	if r.cfg.PendingAge <= 0 {
+		return
+	}
+	now := time.Now()
+	cutoff := now.Add(-r.cfg.PendingAge)
+
+	jobs, err := r.store.StaleJobs(domain.StatusPending, cutoff, reconcileBatch)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load stale pending jobs")
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	// behind a backlog an old pending job may just be waiting its turn,
+	// only an idle queue proves its message is gone
+	backlog, err := r.conn.QueueDepth(r.queues...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read queue depth")
+		return
+	}
+	if backlog > 0 {
+		log.Info().
+			Int("stale_jobs", len(jobs)).
+			Int("backlog", backlog).
+			Msg("Queue has a backlog, leaving stale pending jobs alone")
+		return
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		// waiting in a retry queue, give it the same slack after its retry
+		if job.NextRetryAt != nil && job.NextRetryAt.Add(r.cfg.PendingAge).After(now) {
+			continue
+		}
+
+		if job.Republished >= maxRepublish {
+			log.Warn().
+				Str("job_id", job.ID).
+				Int("republished", job.Republished).
+				Msg("Job still pending with an empty queue after every re-publish, failing it")
+			reason := fmt.Sprintf("job was not picked up by a worker after %d re-publishes", job.Republished)
+			r.fail(job, cutoff, reason)
+			continue
+		}
+		r.republish(ctx, job, cutoff)
+	}
+}
+
+func (r *Reconciler) reconcileProcessing(ctx context.Context) {
+	if r.cfg.ProcessingAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-r.cfg.ProcessingAge)
+
+	jobs, err := r.store.StaleJobs(domain.StatusProcessing, cutoff, reconcileBatch)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load stale processing jobs")
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		reason := fmt.Sprintf("job was processing for over %s, its worker stopped before finishing", r.cfg.ProcessingAge)
+		r.fail(job, cutoff, reason)
+	}
+}
+
+func (r *Reconciler) republish(ctx context.Context, job *domain.Job, cutoff time.Time) {
+	republished, err := r.store.RepublishStaleJob(job.ID, cutoff)
+	if errors.Is(err, domain.ErrJobChanged) {
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to mark job republished")
+		return
+	}
+	job = republished
+
+	// a duplicate of a message that was only delayed is skipped by the
+	// worker once the job finished
+	if err := r.publisher.Publish(ctx, jobMessage(job), job.Priority.AMQP()); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to republish stale job")
+		return
+	}
+	log.Warn().
+		Str("job_id", job.ID).
+		Int("republished", job.Republished).
+		Msg("Republished job stuck in pending")
+}
+
+func (r *Reconciler) fail(job *domain.Job, cutoff time.Time, reason string) {
+	err := r.store.FailStaleJob(job.ID, job.Status, cutoff, reason)
+	if errors.Is(err, domain.ErrJobChanged) {
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to fail stale job")
+		return
+	}
+	log.Warn().Str("job_id", job.ID).Str("reason", reason).Msg("Failed stale job")
+	r.notifier.Notify(job.ID)
+}
@@ -0,0 +1,113 @@
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+	"github.com/rs/zerolog/log"
+)
+
+// isTransient reports whether a retry of a failed scrape may succeed:
+// timeouts, dropped connections, rate limits and server errors anywhere in
+// the error chain. Everything else, e.g. a 404 or an unparseable page,
+// fails the job right away.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, inner := range multi.Unwrap() {
+			if isTransient(inner) {
+				return true
+			}
+		}
+		return false
+	}
+	if transientCause(err) {
+		return true
+	}
+	return isTransient(errors.Unwrap(err))
+}
+
+func transientCause(err error) bool {
+	if err == context.DeadlineExceeded || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	switch e := err.(type) {
+	case interface{ HTTPStatus() int }:
+		code := e.HTTPStatus()
+		return code == http.StatusRequestTimeout ||
+			code == http.StatusTooEarly ||
+			code == http.StatusTooManyRequests ||
+			code >= http.StatusInternalServerError
+	case *net.DNSError:
+		return !e.IsNotFound
+	case *net.OpError:
+		// refused or reset connections, but not unknown hosts
+		var dnsErr *net.DNSError
+		if errors.As(e, &dnsErr) {
+			return !dnsErr.IsNotFound
+		}
+		return true
+	case net.Error:
+		return e.Timeout()
+	}
+	return false
+}
+
+// retryQueue picks the queue of the next retry of a job that already went
+// through retries, false when the cause is permanent or the retries are
+// used up.
+func retryQueue(queues []mq.RetryQueue, retries int, cause error) (mq.RetryQueue, bool) {
+	if retries < 0 || retries >= len(queues) || !isTransient(cause) {
+		return mq.RetryQueue{}, false
+	}
+	return queues[retries], true
+}
+
+// retryLater parks a transiently failed job in the retry queue of its next
+// retry, from where it comes back to the main queue after the backoff. It
+// reports whether the failure was handled; otherwise the job has to fail.
+func (w *ScrapeWorker) retryLater(ctx context.Context, payload MsgBody, priority uint8, cause error, timeline []domain.Stage) bool {
+	queue, ok := retryQueue(w.cfg.RetryQueues, payload.Retry, cause)
+	if !ok {
+		return false
+	}
+
+	err := w.store.ScheduleRetry(payload.ID, cause.Error(), timeline, time.Now().Add(queue.Delay))
+	if errors.Is(err, domain.ErrJobCancelled) {
+		return true
+	}
+	if err != nil {
+		log.Error().Err(err).Str("job_id", payload.ID).Msg("Failed to schedule retry")
+		return false
+	}
+
+	payload.Retry++
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	// the message context may be what timed out the scrape
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := w.publisher.PublishToQueue(pubCtx, queue.Name, body, priority); err != nil {
+		log.Error().Err(err).Str("job_id", payload.ID).Msg("Failed to publish retry")
+		return false
+	}
+
+	log.Info().
+		Str("job_id", payload.ID).
+		Int("retry", payload.Retry).
+		Dur("delay", queue.Delay).
+		Msg("Scrape failed transiently, retry scheduled")
+	return true
+}
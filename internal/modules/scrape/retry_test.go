@@ -0,0 +1,90 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape/engine"
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	status := func(code int) error {
+		return &engine.StatusError{URL: "https://example.com", StatusCode: code}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("unparseable page"), false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+		{"eof", io.EOF, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"wrapped eof", fmt.Errorf("reading body: %w", io.EOF), true},
+		{"not found", status(404), false},
+		{"forbidden", status(403), false},
+		{"request timeout", status(408), true},
+		{"too early", status(425), true},
+		{"too many requests", status(429), true},
+		{"server error", status(500), true},
+		{"bad gateway", fmt.Errorf("fetch: %w", status(502)), true},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "x.invalid", IsNotFound: true}, false},
+		{"dns failure", &net.DNSError{Err: "server misbehaving", Name: "example.com"}, true},
+		{"refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"dial unknown host", &net.OpError{Op: "dial", Err: &net.DNSError{Name: "x.invalid", IsNotFound: true}}, false},
+		{"net timeout", timeoutError{}, true},
+		{"joined permanent", errors.Join(status(404), errors.New("no fallback")), false},
+		{"joined transient", errors.Join(status(404), context.DeadlineExceeded), true},
+	}
+
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("%s: isTransient(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryQueue(t *testing.T) {
+	queues := []mq.RetryQueue{
+		{Name: "scrape.retry.10s", Delay: 10 * time.Second},
+		{Name: "scrape.retry.20s", Delay: 20 * time.Second},
+	}
+
+	tests := []struct {
+		name    string
+		queues  []mq.RetryQueue
+		retries int
+		cause   error
+		want    string
+		ok      bool
+	}{
+		{"first retry", queues, 0, io.EOF, "scrape.retry.10s", true},
+		{"second retry", queues, 1, io.EOF, "scrape.retry.20s", true},
+		{"used up", queues, 2, io.EOF, "", false},
+		{"permanent", queues, 0, errors.New("unparseable page"), "", false},
+		{"retries disabled", nil, 0, io.EOF, "", false},
+		{"negative", queues, -1, io.EOF, "", false},
+	}
+
+	for _, tt := range tests {
+		q, ok := retryQueue(tt.queues, tt.retries, tt.cause)
+		if ok != tt.ok || q.Name != tt.want {
+			t.Errorf("%s: retryQueue = %q, %v, want %q, %v", tt.name, q.Name, ok, tt.want, tt.ok)
+		}
+	}
+}
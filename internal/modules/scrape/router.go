@@ -1,10 +1,22 @@
 package scrape
 
-import "github.com/go-chi/chi/v5"
+import (
+	"net/http"
 
-func Routes(h *Handler) chi.Router {
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes mounts the scrape API. verify guards the endpoints that enqueue
+// scrapes, typically with human verification for browser clients.
+func Routes(h *Handler, verify func(http.Handler) http.Handler) chi.Router {
 	r := chi.NewRouter()
-	r.Post("/", h.SubmitScrape)
+	r.Get("/", h.ListJobs)
+	r.With(verify).Post("/", h.SubmitScrape)
+	r.With(verify).Post("/batch", h.SubmitBatch)
+	r.Get("/batch/{id}", h.GetBatchStatus)
 	r.Get("/{id}", h.GetStatus)
+	r.Delete("/{id}", h.CancelJob)
+	r.With(verify).Post("/{id}/retry", h.RetryJob)
+	r.Get("/{id}/events", h.StreamEvents)
 	return r
 }
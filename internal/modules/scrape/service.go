@@ -3,53 +3,483 @@ package scrape
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/Alkush-Pipania/Scrapper/config"
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
 	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
 	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/Alkush-Pipania/Scrapper/pkg/urlutil"
 	"github.com/google/uuid"
 )
 
+const (
+	maxBatchSize = 2000
+	// batchPublishers bounds the publishes of a batch waiting for their
+	// confirms at the same time.
+	batchPublishers = 16
+)
+
+var (
+	ErrEmptyBatch    = errors.New("batch has no items")
+	ErrBatchTooLarge = fmt.Errorf("batch exceeds %d items", maxBatchSize)
+	ErrEnqueueFailed = errors.New("job could not be enqueued")
+)
+
+// ServiceConfig holds the submission knobs of the scrape service.
+type ServiceConfig struct {
+	Cache          config.CacheConfig
+	IdempotencyTTL time.Duration
+}
+
 type service struct {
-	rds  *redis.Client
-	mqch *mq.Publisher
+	rds      *redis.Client
+	mqch     *mq.Publisher
+	notifier *Notifier
+	cfg      ServiceConfig
 }
 
-func NewService(rds *redis.Client, mqch *mq.Publisher) *service {
+func NewService(rds *redis.Client, mqch *mq.Publisher, notifier *Notifier, cfg ServiceConfig) *service {
 	return &service{
-		rds:  rds,
-		mqch: mqch,
+		rds:      rds,
+		mqch:     mqch,
+		notifier: notifier,
+		cfg:      cfg,
 	}
 }
 
 func (s *service) SubmitJob(ctx context.Context, req SubmitScrapeRequest) (string, error) {
-	jobID := uuid.NewString()
-	if err := s.rds.CreateJob(jobID, req.URL); err != nil {
+	if err := req.Options.Validate(); err != nil {
+		return "", err
+	}
+	key := auth.APIKeyFromContext(ctx)
+	if key != nil {
+		if err := key.Permits(req.Options); err != nil {
+			return "", err
+		}
+	}
+	job := newJob(ctx, req)
+
+	// keys are scoped per API key so callers cannot collide
+	idemKey := req.IdempotencyKey
+	if idemKey != "" && key != nil {
+		idemKey = key.ID + ":" + idemKey
+	}
+	if idemKey != "" {
+		existing, err := s.rds.ReserveIdempotencyKey(idemKey, job.ID, s.cfg.IdempotencyTTL)
+		if err != nil {
+			return "", err
+		}
+		if existing != "" {
+			return existing, nil
+		}
+	}
+
+	err := s.consumeQuota(key, 1)
+	if err == nil {
+		if err = s.createAndPublish(ctx, job, req); err != nil {
+			s.refundQuota(key, 1)
+		}
+	}
+	if err != nil {
+		if idemKey != "" {
+			_ = s.rds.ReleaseIdempotencyKey(idemKey)
+		}
 		return "", err
 	}
+	return job.ID, nil
+}
+
+func (s *service) consumeQuota(key *auth.APIKey, n int) error {
+	if key == nil {
+		return nil
+	}
+	return s.rds.ConsumeQuota(key, n)
+}
+
+// refundQuota gives back quota consumed for submissions that never got
+// enqueued.
+func (s *service) refundQuota(key *auth.APIKey, n int) {
+	if key == nil || n == 0 {
+		return
+	}
+	if err := s.rds.RefundQuota(key.ID, n); err != nil {
+		log.Printf("failed to refund quota of key %v : %v", key.ID, err)
+	}
+}
+
+func (s *service) createAndPublish(ctx context.Context, job *domain.Job, req SubmitScrapeRequest) error {
+	s.fromCache(job, req)
+	if err := s.rds.CreateJob(job); err != nil {
+		return err
+	}
+	if job.Status == domain.StatusCompleted {
+		// served from the cache, the callback still fires
+		s.notifier.Notify(job.ID)
+		return nil
+	}
+	return s.publish(ctx, job)
+}
+
+func (s *service) SubmitBatch(ctx context.Context, req SubmitBatchRequest) (*SubmitBatchResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(req.Items) > maxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	key := auth.APIKeyFromContext(ctx)
+	for i, item := range req.Items {
+		if err := item.Options.Validate(); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if key != nil {
+			if err := key.Permits(item.Options); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	}
+	if err := s.consumeQuota(key, len(req.Items)); err != nil {
+		return nil, err
+	}
+
+	batchID := uuid.NewString()
+	jobs := make([]*domain.Job, len(req.Items))
+	jobIDs := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		if item.Priority == "" {
+			item.Priority = req.Priority
+		}
+		jobs[i] = newJob(ctx, item)
+		s.fromCache(jobs[i], item)
+		jobIDs[i] = jobs[i].ID
+	}
+
+	if err := s.rds.CreateBatch(batchID, jobs); err != nil {
+		s.refundQuota(key, len(jobs))
+		return nil, err
+	}
+
+	// the batch is stored, so it is returned even when some publishes fail:
+	// those jobs are marked failed and can be retried one by one instead of
+	// resubmitting the jobs that did get enqueued
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []BatchItemError
+		sem    = make(chan struct{}, batchPublishers)
+	)
+	for i, job := range jobs {
+		if job.Status == domain.StatusCompleted {
+			s.notifier.Notify(job.ID)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, job *domain.Job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.publish(ctx, job); err != nil {
+				mu.Lock()
+				failed = append(failed, BatchItemError{Index: i, JobID: job.ID, Error: err.Error()})
+				mu.Unlock()
+			}
+		}(i, job)
+	}
+	wg.Wait()
+
+	s.refundQuota(key, len(failed))
+	slices.SortFunc(failed, func(a, b BatchItemError) int { return a.Index - b.Index })
+
+	return &SubmitBatchResponse{
+		BatchID: batchID,
+		JobIDs:  jobIDs,
+		Failed:  failed,
+	}, nil
+}
+
+func newJob(ctx context.Context, req SubmitScrapeRequest) *domain.Job {
+	job := &domain.Job{
+		ID:             uuid.NewString(),
+		URL:            req.URL,
+		Options:        req.Options,
+		Priority:       req.Priority,
+		CallbackURL:    req.CallbackURL,
+		CallbackSecret: req.CallbackSecret,
+		APIKeyID:       auth.KeyIDFrom(ctx),
+		Attempt:        1,
+	}
+	return job
+}
+
+// fromCache completes the job with a cached result when one exists that is
+// fresh enough for the request.
+func (s *service) fromCache(job *domain.Job, req SubmitScrapeRequest) {
+	// cached results never carry raw HTML
+	if s.cfg.Cache.TTL <= 0 || req.ForceRefresh || req.Options.IncludeHTML {
+		return
+	}
+	maxAge := s.cfg.Cache.MaxAge
+	if req.MaxAge != nil {
+		maxAge = time.Duration(*req.MaxAge) * time.Second
+	}
+	if maxAge <= 0 {
+		return
+	}
+
+	cached, err := s.rds.GetCachedResult(urlutil.Key(job.URL), req.Options.Fingerprint())
+	if err != nil || cached.Data == nil {
+		return
+	}
+	if time.Since(cached.CachedAt) > maxAge {
+		return
+	}
+
+	job.Status = domain.StatusCompleted
+	job.Result = cached.Data
+	job.CachedAt = &cached.CachedAt
+}
+
+func (s *service) publish(ctx context.Context, job *domain.Job) error {
+	msgBody := jobMessage(job)
+
+	// a client going away must not abort a publish the broker may already
+	// have, the confirm timeout bounds the wait
+	err := s.mqch.Publish(context.WithoutCancel(ctx), msgBody, job.Priority.AMQP())
+	if err != nil {
+		log.Printf("failed to publish job , id : %v and error : %v", job.ID, err)
+		// nothing will pick the job up, do not leave it pending
+		if failErr := s.rds.FailJob(job.ID, "enqueue failed: "+err.Error(), nil); failErr != nil {
+			log.Printf("failed to mark job %v as failed : %v", job.ID, failErr)
+		}
+		return fmt.Errorf("%w: %w", ErrEnqueueFailed, err)
+	}
+	return nil
+}
+
+// jobMessage is the queue message that runs job. It carries the retries
+// the job already went through so a re-publish keeps its retry budget.
+func jobMessage(job *domain.Job) []byte {
 	msgBody, _ := json.Marshal(&MsgBody{
-		URL: req.URL,
-		ID:  jobID,
+		URL:     job.URL,
+		ID:      job.ID,
+		Options: job.Options,
+		Retry:   job.Retries,
 	})
+	return msgBody
+}
 
-	err := s.mqch.Publish(ctx, msgBody)
+// ownJob loads a job of the caller. Jobs of another key are reported as
+// not found so their IDs cannot be probed.
+func (s *service) ownJob(ctx context.Context, jobID string) (*domain.Job, error) {
+	job, err := s.rds.GetJob(jobID)
 	if err != nil {
-		log.Printf("failed to publish job , id : %v and error : %v", jobID, err)
-		return "", err
+		return nil, err
 	}
-	return jobID, nil
+	if job.APIKeyID != auth.KeyIDFrom(ctx) {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
 }
 
 func (s *service) GetJobStatus(ctx context.Context, jobID string) (*ScrapeStatusResponse, error) {
-	job, err := s.rds.GetJob(jobID)
+	job, err := s.ownJob(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
-	return &ScrapeStatusResponse{
-		ID:     job.ID,
-		URL:    job.URL,
-		Status: string(job.Status),
-		Result: job.Result,
-		Error:  job.Error,
-	}, nil
+	return toStatusResponse(job), nil
+}
+
+func (s *service) GetBatchStatus(ctx context.Context, batchID string) (*BatchStatusResponse, error) {
+	jobs, err := s.rds.GetBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	keyID := auth.KeyIDFrom(ctx)
+	for _, job := range jobs {
+		if job.APIKeyID != keyID {
+			return nil, ErrBatchNotFound
+		}
+	}
+
+	resp := &BatchStatusResponse{
+		BatchID: batchID,
+		Total:   len(jobs),
+		Counts: map[string]int{
+			string(domain.StatusPending):    0,
+			string(domain.StatusProcessing): 0,
+			string(domain.StatusCompleted):  0,
+			string(domain.StatusFailed):     0,
+			string(domain.StatusCancelled):  0,
+		},
+		Jobs: make([]ScrapeStatusResponse, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		resp.Counts[string(job.Status)]++
+
+		// results can be large, clients fetch them per job
+		item := toStatusResponse(job)
+		item.Result = nil
+		resp.Jobs = append(resp.Jobs, *item)
+	}
+	return resp, nil
+}
+
+func (s *service) ListJobs(ctx context.Context, req ListJobsRequest) (*ListJobsResponse, error) {
+	keyID := auth.KeyIDFrom(ctx)
+	if keyID == "" {
+		return nil, ErrAPIKeyRequired
+	}
+	jobs, next, err := s.rds.ListJobs(domain.JobFilter{
+		APIKeyID: keyID,
+		Status:   domain.JobStatus(req.Status),
+		Domain:   req.Domain,
+		Since:    req.Since,
+		Cursor:   req.Cursor,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListJobsResponse{
+		Jobs:       make([]ScrapeStatusResponse, 0, len(jobs)),
+		NextCursor: next,
+	}
+	for _, job := range jobs {
+		item := toStatusResponse(job)
+		item.Result = nil
+		resp.Jobs = append(resp.Jobs, *item)
+	}
+	return resp, nil
+}
+
+// CancelJob cancels a pending job outright and signals the worker running a
+// processing one so its scrape context is cancelled.
+func (s *service) CancelJob(ctx context.Context, jobID string) (*ScrapeStatusResponse, error) {
+	if _, err := s.ownJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	job, err := s.rds.CancelJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// also sent for pending jobs, a worker may have picked it up meanwhile
+	if err := s.rds.PublishCancel(jobID); err != nil {
+		log.Printf("failed to signal cancellation, id : %v and error : %v", jobID, err)
+	}
+	return toStatusResponse(job), nil
+}
+
+// RetryJob re-enqueues a finished job under its existing ID. A retry counts
+// against the caller's quota like a new submission.
+func (s *service) RetryJob(ctx context.Context, jobID string, req RetryJobRequest) (*ScrapeStatusResponse, error) {
+	job, err := s.ownJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.StatusFailed && job.Status != domain.StatusCompleted {
+		return nil, ErrNotRetryable
+	}
+
+	opts := job.Options
+	if req.Options != nil {
+		if err := req.Options.Validate(); err != nil {
+			return nil, err
+		}
+		opts = *req.Options
+	}
+	key := auth.APIKeyFromContext(ctx)
+	if key != nil {
+		if err := key.Permits(opts); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.consumeQuota(key, 1); err != nil {
+		return nil, err
+	}
+
+	job, err = s.rds.RetryJob(jobID, req.Options)
+	if err != nil {
+		s.refundQuota(key, 1)
+		return nil, err
+	}
+	if err := s.publish(ctx, job); err != nil {
+		s.refundQuota(key, 1)
+		return nil, err
+	}
+	return toStatusResponse(job), nil
+}
+
+// WatchJob emits the current state of a job followed by every transition,
+// and closes the stream once the job reaches a terminal status.
+func (s *service) WatchJob(ctx context.Context, jobID string) (<-chan *ScrapeStatusResponse, error) {
+	events, closeSub, err := s.rds.SubscribeJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.ownJob(ctx, jobID)
+	if err != nil {
+		_ = closeSub()
+		return nil, err
+	}
+
+	out := make(chan *ScrapeStatusResponse, 1)
+	out <- toStatusResponse(job)
+
+	go func() {
+		defer close(out)
+		defer closeSub()
+
+		if job.Status.Terminal() {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case out <- toStatusResponse(ev):
+				case <-ctx.Done():
+					return
+				}
+				if ev.Status.Terminal() {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// WaitForJob blocks until the job reaches a terminal status or the timeout
+// expires, and returns the last state it saw.
+func (s *service) WaitForJob(ctx context.Context, jobID string, timeout time.Duration) (*ScrapeStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	events, err := s.WatchJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *ScrapeStatusResponse
+	for ev := range events {
+		last = ev
+	}
+	return last, nil
 }
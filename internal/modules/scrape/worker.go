@@ -3,43 +3,117 @@ package scrape
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/Alkush-Pipania/Scrapper/config"
 	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
 	"github.com/Alkush-Pipania/Scrapper/internal/modules/scrape/engine"
+	"github.com/Alkush-Pipania/Scrapper/pkg/mq"
 	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+	"github.com/Alkush-Pipania/Scrapper/pkg/urlutil"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
+// WorkerConfig holds the settings applied around each scrape.
+type WorkerConfig struct {
+	CacheTTL time.Duration
+	// ChangeRetention keeps snapshots and change records, 0 disables
+	// change detection.
+	ChangeRetention time.Duration
+	Politeness      config.PolitenessConfig
+	// RetryQueues park transiently failed jobs, one queue per retry. A job
+	// fails once they are used up.
+	RetryQueues []mq.RetryQueue
+	// DeferQueue holds jobs waiting for their host, see mq.DeferQueue.
+	DeferQueue string
+}
+
 type ScrapeWorker struct {
-	store   *redis.Client
-	scraper *engine.Scraper
+	store     *redis.Client
+	scraper   *engine.Scraper
+	notifier  *Notifier
+	publisher *mq.Publisher
+	cfg       WorkerConfig
+
+	mu sync.Mutex
+	// running holds the cancel funcs of the scrapes in progress by job and
+	// run, a duplicate delivery may run alongside the first one
+	running map[string]map[uint64]context.CancelCauseFunc
+	nextRun uint64
 }
 
-func NewScrapeWorker(store *redis.Client, scraper *engine.Scraper) *ScrapeWorker {
+func NewScrapeWorker(store *redis.Client, scraper *engine.Scraper, notifier *Notifier, publisher *mq.Publisher, cfg WorkerConfig) *ScrapeWorker {
 	return &ScrapeWorker{
-		store:   store,
-		scraper: scraper,
+		store:     store,
+		scraper:   scraper,
+		notifier:  notifier,
+		publisher: publisher,
+		cfg:       cfg,
+		running:   make(map[string]map[uint64]context.CancelCauseFunc),
 	}
 }
 
 func (w *ScrapeWorker) Handle(ctx context.Context, msg amqp091.Delivery) error {
 	var payload MsgBody
 	if err := json.Unmarshal(msg.Body, &payload); err != nil {
+		// dead-lettered so the payload can be inspected
 		log.Error().Err(err).Msg("Failed to unmarshal job payload")
-		return nil
+		return fmt.Errorf("invalid job payload: %w", err)
 	}
 
 	if payload.ID == "" || payload.URL == "" {
-		log.Error().Msg("Job missing ID or URL, skipping")
+		log.Error().Msg("Job missing ID or URL")
+		return errors.New("job payload missing id or url")
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// track before starting so a cancel racing with the start still lands
+	run := w.track(payload.ID, cancel)
+	defer w.untrack(payload.ID, run)
+
+	// the job stays pending while it waits for its host
+	release, wait := w.acquireHost(payload.ID, payload.URL)
+	if release == nil {
+		if err := w.deferJob(ctx, msg.Body, msg.Priority, wait); err != nil {
+			log.Warn().Err(err).Str("job_id", payload.ID).Msg("Failed to defer job, requeueing")
+			return fmt.Errorf("job %s: %w", payload.ID, errHostBusy)
+		}
+		log.Debug().Str("job_id", payload.ID).Str("url", payload.URL).Dur("wait", wait).Msg("Host busy, job deferred")
 		return nil
 	}
+	defer release()
 
-	log.Info().Str("job_id", payload.ID).Str("url", payload.URL).Msg("Starting scrape")
+	if err := w.store.StartJob(payload.ID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrJobCancelled):
+			log.Info().Str("job_id", payload.ID).Msg("Job cancelled before start, skipping")
+			return nil
+		case errors.Is(err, domain.ErrJobFinished):
+			// the reconciler re-published a message that was only delayed
+			log.Info().Str("job_id", payload.ID).Msg("Job already finished, skipping duplicate delivery")
+			return nil
+		case errors.Is(err, domain.ErrJobNotFound):
+			// expired or never stored, nobody can read a result
+			log.Warn().Str("job_id", payload.ID).Msg("Job not found, dropping message")
+			return nil
+		}
+		log.Error().Err(err).Str("job_id", payload.ID).Msg("Failed to start job")
+		return fmt.Errorf("starting job %s: %w", payload.ID, err)
+	}
 
-	_ = w.store.UpdateStatus(payload.ID, domain.StatusProcessing)
+	log.Info().Str("job_id", payload.ID).Str("url", payload.URL).Msg("Starting scrape")
 
-	data, err := w.scraper.Scrape(ctx, payload.URL)
+	data, timeline, err := w.scraper.Scrape(ctx, payload.URL, payload.Options)
+	if errors.Is(context.Cause(ctx), domain.ErrJobCancelled) {
+		log.Info().Str("job_id", payload.ID).Msg("Scrape cancelled")
+		return nil
+	}
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -47,9 +121,17 @@ func (w *ScrapeWorker) Handle(ctx context.Context, msg amqp091.Delivery) error {
 			Str("url", payload.URL).
 			Msg("Scrape failed")
 
-		if storeErr := w.store.FailJob(payload.ID, err.Error()); storeErr != nil {
+		if w.retryLater(ctx, payload, msg.Priority, err, timeline) {
+			return nil
+		}
+		if storeErr := w.store.FailJob(payload.ID, err.Error(), timeline); storeErr != nil {
+			if errors.Is(storeErr, domain.ErrJobCancelled) {
+				return nil
+			}
 			log.Error().Err(storeErr).Msg("Failed to update job status to failed")
+			return fmt.Errorf("failing job %s: %w", payload.ID, storeErr)
 		}
+		w.notifier.Notify(payload.ID)
 		return nil
 	}
 
@@ -63,5 +145,115 @@ func (w *ScrapeWorker) Handle(ctx context.Context, msg amqp091.Delivery) error {
 		Str("site_name", data.SiteName).
 		Msg("Scrape completed successfully")
 
-	return w.store.UpdateResult(payload.ID, data)
+	if err := w.store.UpdateResult(payload.ID, data, timeline); err != nil {
+		if errors.Is(err, domain.ErrJobCancelled) {
+			return nil
+		}
+		return err
+	}
+	stripped := *data
+	stripped.HTML = ""
+	if w.cfg.CacheTTL > 0 {
+		if err := w.store.CacheResult(urlutil.Key(payload.URL), payload.Options.Fingerprint(), &stripped, w.cfg.CacheTTL); err != nil {
+			log.Warn().Err(err).Str("job_id", payload.ID).Msg("Failed to cache scrape result")
+		}
+	}
+	if w.cfg.ChangeRetention > 0 {
+		w.trackChanges(payload, &stripped)
+	}
+	w.notifier.Notify(payload.ID)
+	return nil
+}
+
+// trackChanges replaces the URL snapshot of the job's key and options with
+// data and records a change when it differs from the previous scrape.
+func (w *ScrapeWorker) trackChanges(payload MsgBody, data *domain.ScrapedData) {
+	job, err := w.store.GetJob(payload.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", payload.ID).Msg("Failed to load job for change tracking")
+		return
+	}
+	// history is only readable with an API key
+	if job.APIKeyID == "" {
+		return
+	}
+	snap := &domain.Snapshot{
+		URL:                payload.URL,
+		URLHash:            urlutil.Key(payload.URL),
+		JobID:              payload.ID,
+		Data:               data,
+		ScrapedAt:          time.Now(),
+		APIKeyID:           job.APIKeyID,
+		OptionsFingerprint: payload.Options.Fingerprint(),
+	}
+
+	prev, err := w.store.SwapSnapshot(snap, w.cfg.ChangeRetention)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", payload.ID).Msg("Failed to store snapshot")
+		return
+	}
+
+	change := diffSnapshots(prev, snap)
+	if change == nil {
+		return
+	}
+	if err := w.store.AddChange(job.APIKeyID, change, w.cfg.ChangeRetention, maxChangeRecords); err != nil {
+		log.Warn().Err(err).Str("job_id", payload.ID).Msg("Failed to record change")
+		return
+	}
+	log.Info().
+		Str("job_id", payload.ID).
+		Strs("fields", change.Fields).
+		Float64("similarity", change.Similarity).
+		Msg("Content change detected")
+}
+
+// ListenForCancels aborts running scrapes when their job is cancelled
+// through the API. It blocks until ctx is done.
+func (w *ScrapeWorker) ListenForCancels(ctx context.Context) {
+	for ctx.Err() == nil {
+		ids, err := w.store.SubscribeCancels(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to subscribe to job cancellations")
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		for id := range ids {
+			w.mu.Lock()
+			for _, cancel := range w.running[id] {
+				cancel(domain.ErrJobCancelled)
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// track registers the cancel func of a run of job id and returns the run
+// to untrack.
+func (w *ScrapeWorker) track(id string, cancel context.CancelCauseFunc) uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextRun++
+	runs, ok := w.running[id]
+	if !ok {
+		runs = make(map[uint64]context.CancelCauseFunc)
+		w.running[id] = runs
+	}
+	runs[w.nextRun] = cancel
+	return w.nextRun
+}
+
+func (w *ScrapeWorker) untrack(id string, run uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.running[id], run)
+	if len(w.running[id]) == 0 {
+		delete(w.running, id)
+	}
 }
@@ -0,0 +1,33 @@
+package urls
+
+import (
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+const (
+	defaultChangesLimit = 20
+	maxChangesLimit     = 100
+)
+
+type ListChangesRequest struct {
+	Hash               string
+	OptionsFingerprint string
+	Limit              int
+}
+
+type ChangesResponse struct {
+	URLHash            string           `json:"url_hash"`
+	OptionsFingerprint string           `json:"options_fingerprint"`
+	URL                string           `json:"url"`
+	LastJobID          string           `json:"last_job_id"`
+	LastScrapedAt      time.Time        `json:"last_scraped_at"`
+	Changes            []*domain.Change `json:"changes"`
+}
+
+var (
+	ErrURLNotFound    = domain.ErrSnapshotNotFound
+	ErrAPIKeyRequired = auth.ErrAPIKeyRequired
+)
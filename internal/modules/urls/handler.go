@@ -0,0 +1,78 @@
+package urls
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/internal/httpx"
+	"github.com/Alkush-Pipania/Scrapper/pkg/validate"
+	"github.com/go-chi/chi/v5"
+)
+
+type Service interface {
+	ListChanges(context.Context, ListChangesRequest) (*ChangesResponse, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListChanges(r)
+	if err != nil {
+		httpx.WriteValidationError(w, err)
+		return
+	}
+
+	resp, err := h.service.ListChanges(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrURLNotFound):
+			httpx.WriteError(w, http.StatusNotFound, "url_not_found", "URL has not been scraped")
+		case errors.Is(err, ErrAPIKeyRequired):
+			httpx.WriteError(w, http.StatusUnauthorized, "missing_api_key", "Change history requires an API key")
+		default:
+			httpx.WriteInternal(w)
+		}
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// parseListChanges reads the URL hash and the options fingerprint, as
+// returned in url_hash and options_fingerprint by the job status, and the
+// optional limit. Without a fingerprint the default options are assumed.
+func parseListChanges(r *http.Request) (ListChangesRequest, error) {
+	req := ListChangesRequest{
+		Hash:               chi.URLParam(r, "hash"),
+		OptionsFingerprint: r.URL.Query().Get("options_fingerprint"),
+		Limit:              defaultChangesLimit,
+	}
+
+	if b, err := hex.DecodeString(req.Hash); err != nil || len(b) != 16 {
+		return req, validate.Errorf("hash", "must be a 32 character hex url_hash")
+	}
+	if req.OptionsFingerprint == "" {
+		req.OptionsFingerprint = domain.ScrapeOptions{}.Fingerprint()
+	} else if b, err := hex.DecodeString(req.OptionsFingerprint); err != nil || len(b) != 8 {
+		return req, validate.Errorf("options_fingerprint", "must be a 16 character hex options_fingerprint")
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return req, validate.Errorf("limit", "must be a positive integer")
+		}
+		req.Limit = min(n, maxChangesLimit)
+	}
+	return req, nil
+}
@@ -0,0 +1,9 @@
+package urls
+
+import "github.com/go-chi/chi/v5"
+
+func Routes(h *Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/{hash}/changes", h.ListChanges)
+	return r
+}
@@ -0,0 +1,45 @@
+package urls
+
+import (
+	"context"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/Alkush-Pipania/Scrapper/pkg/redis"
+)
+
+type service struct {
+	rds *redis.Client
+}
+
+func NewService(rds *redis.Client) *service {
+	return &service{rds: rds}
+}
+
+// ListChanges returns the history of a URL as scraped by the caller's key
+// with the requested options; other keys' scrapes are never visible.
+// Anonymous scrapes keep no history.
+func (s *service) ListChanges(ctx context.Context, req ListChangesRequest) (*ChangesResponse, error) {
+	keyID := auth.KeyIDFrom(ctx)
+	if keyID == "" {
+		return nil, ErrAPIKeyRequired
+	}
+
+	snap, err := s.rds.GetSnapshot(keyID, req.OptionsFingerprint, req.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.rds.ListChanges(keyID, req.OptionsFingerprint, req.Hash, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangesResponse{
+		URLHash:            snap.URLHash,
+		OptionsFingerprint: snap.OptionsFingerprint,
+		URL:                snap.URL,
+		LastJobID:          snap.JobID,
+		LastScrapedAt:      snap.ScrapedAt,
+		Changes:            changes,
+	}, nil
+}
@@ -19,7 +19,7 @@ func New(handler http.Handler, port string, logger *zerolog.Logger) *Server {
 			Addr:         ":" + port,
 			Handler:      handler,
 			ReadTimeout:  10 * time.Second,
-			WriteTimeout: 10 * time.Second,
+			WriteTimeout: 10 * time.Second, // streaming handlers lift this via http.ResponseController
 			IdleTimeout:  1 * time.Minute,
 		},
 		logger: logger,
@@ -20,8 +20,28 @@ type Client struct {
 type Result struct {
 	Title       string
 	ContentText string
-	// HTML        string
-	Screenshot []byte
+	HTML        string
+	Screenshot  []byte
+}
+
+// Options selects the optional parts of the BrowserQL query. Screenshots
+// are the expensive part, so callers only ask for them when needed.
+type Options struct {
+	Screenshot bool
+	HTML       bool
+}
+
+// StatusError is returned when Browserless answers with an HTTP error.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("browserless error code: %d", e.StatusCode)
+}
+
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
 }
 
 func New(endpoint string, token string) *Client {
@@ -34,7 +54,7 @@ func New(endpoint string, token string) *Client {
 	}
 }
 
-func (b *Client) Scrape(ctx context.Context, targetURL string) (*Result, error) {
+func (b *Client) Scrape(ctx context.Context, targetURL string, opts Options) (*Result, error) {
 	query := `
 	mutation Scrape {
 	  goto(url: "%s") {
@@ -46,14 +66,25 @@ func (b *Client) Scrape(ctx context.Context, targetURL string) (*Result, error)
 	  }
 	  pageTitle: text(selector: "title") {
 		text
-	  }
+	  }%s%s
+	}`
+
+	var htmlField, shotField string
+	if opts.HTML {
+		htmlField = `
+	  pageHTML: html {
+		html
+	  }`
+	}
+	if opts.Screenshot {
+		shotField = `
 	  shot: screenshot(fullPage: false, type: jpeg, quality: 75) {
 		base64
-	  }
-	}`
+	  }`
+	}
 
 	payload := map[string]string{
-		"query": fmt.Sprintf(query, targetURL),
+		"query": fmt.Sprintf(query, targetURL, htmlField, shotField),
 	}
 	jsonPayload, _ := json.Marshal(payload)
 
@@ -72,7 +103,7 @@ func (b *Client) Scrape(ctx context.Context, targetURL string) (*Result, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("browserless error code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Parse Response (Internal struct just for unmarshalling)
@@ -88,6 +119,9 @@ func (b *Client) Scrape(ctx context.Context, targetURL string) (*Result, error)
 			PageTitle struct {
 				Text string `json:"text"`
 			} `json:"pageTitle"`
+			PageHTML struct {
+				HTML string `json:"html"`
+			} `json:"pageHTML"`
 			Shot struct {
 				Base64 string `json:"base64"`
 			} `json:"shot"`
@@ -109,6 +143,7 @@ func (b *Client) Scrape(ctx context.Context, targetURL string) (*Result, error)
 	return &Result{
 		Title:       strings.TrimSpace(qlResp.Data.PageTitle.Text),
 		ContentText: qlResp.Data.PageText.Text,
+		HTML:        qlResp.Data.PageHTML.HTML,
 		Screenshot:  imgBytes,
 	}, nil
 }
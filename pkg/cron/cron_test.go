@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// 2026-10-14 is a Wednesday
+	from := time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, 10, 14, 10, 8, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.Date(2026, 10, 14, 10, 15, 0, 0, time.UTC)},
+		{"step from value", "5/20 * * * *", time.Date(2026, 10, 14, 10, 25, 0, 0, time.UTC)},
+		{"range", "0 12-14 * * *", time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+		{"range with step", "30 9-17/4 * * *", time.Date(2026, 10, 14, 13, 30, 0, 0, time.UTC)},
+		{"range rolls to next day", "0 1-3 * * *", time.Date(2026, 10, 15, 1, 0, 0, 0, time.UTC)},
+		{"list", "0 0 1,15 * *", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"month", "0 0 1 2 *", time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 0", "0 0 * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"weekday range", "0 9 * * 1-5", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)},
+		{"day of month or week", "0 0 13 * 5", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"macro", "@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"never matches", "0 0 31 2 *", time.Time{}},
+		{"never matches in april", "0 0 31 4 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalid", expr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package hcaptcha
+
+import (
+	"context"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/captcha"
+	"github.com/Alkush-Pipania/Scrapper/pkg/siteverify"
+)
+
+const DefaultVerifyURL = "https://api.hcaptcha.com/siteverify"
+
+var _ captcha.Verifier = (*Client)(nil)
+
+type Client struct {
+	sv *siteverify.Client
+}
+
+// New creates an hCaptcha client. An empty verifyURL uses the public
+// siteverify endpoint.
+func New(secret, verifyURL string) *Client {
+	if verifyURL == "" {
+		verifyURL = DefaultVerifyURL
+	}
+	return &Client{sv: siteverify.New("hcaptcha", secret, verifyURL)}
+}
+
+func (c *Client) Verify(ctx context.Context, token, ip string) error {
+	_, err := c.sv.Verify(ctx, token, ip)
+	return err
+}
@@ -0,0 +1,46 @@
+// Package leader runs periodic work on a single replica at a time.
+package leader
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Locker holds named locks shared by every replica.
+type Locker interface {
+	AcquireLock(name, owner string, ttl time.Duration) (bool, error)
+	ReleaseLock(name, owner string) error
+}
+
+// Run calls fn every tick while this replica holds the named lock, and
+// blocks until ctx is done. Every replica runs the loop; the lock makes sure
+// only the leader calls fn. It is renewed on each tick and outlives a missed
+// one, so a slow round does not hand leadership over.
+func Run(ctx context.Context, locker Locker, name string, tick time.Duration, fn func(context.Context)) {
+	owner := uuid.NewString()
+	ttl := 3 * tick
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	defer locker.ReleaseLock(name, owner)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		leader, err := locker.AcquireLock(name, owner, ttl)
+		if err != nil {
+			log.Error().Err(err).Str("lock", name).Msg("Failed to acquire leader lock")
+			continue
+		}
+		if leader {
+			fn(ctx)
+		}
+	}
+}
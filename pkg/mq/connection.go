@@ -40,9 +40,15 @@ func SetupTopology(conn *amqp091.Connection, rmqCfg *config.RabbitMQConfig) erro
 		return err
 	}
 
+	// changing arguments of an existing queue fails the declare, the queue
+	// has to be deleted first
+	var args amqp091.Table
+	if rmqCfg.MaxPriority > 0 {
+		args = amqp091.Table{"x-max-priority": rmqCfg.MaxPriority}
+	}
 	if _, err := ch.QueueDeclare(
 		rmqCfg.QueueName,
-		true, false, false, false, nil,
+		true, false, false, false, args,
 	); err != nil {
 		return err
 	}
@@ -55,5 +61,8 @@ func SetupTopology(conn *amqp091.Connection, rmqCfg *config.RabbitMQConfig) erro
 	); err != nil {
 		return err
 	}
-	return nil
+	if err := declareRetryQueues(ch, rmqCfg); err != nil {
+		return err
+	}
+	return declareDeadLetters(ch, rmqCfg)
 }
@@ -3,7 +3,9 @@ package mq
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"runtime/debug"
 	"sync"
 	"time"
 
@@ -12,43 +14,96 @@ import (
 )
 
 type Consumer struct {
-	ch          *amqp.Channel
+	conn        *Connection
+	publisher   *Publisher // confirms dead letters before the source is acked
 	queueName   string
+	deadLetter  string // exchange for failed messages, empty drops them
 	workers     int
 	sem         chan struct{}
 	wg          sync.WaitGroup
 	consumerTag string
+
+	mu sync.Mutex
+	ch *amqp.Channel // channel of the current consume session
 }
 
-func NewConsumer(conn *amqp.Connection, queueName string, workers int) (*Consumer, error) {
+func NewConsumer(conn *Connection, publisher *Publisher, queueName, deadLetterExchange string, workers int) (*Consumer, error) {
 	if conn == nil {
 		return nil, errors.New("amqp connection is nil")
 	}
-	ch, err := conn.Channel()
-	if err != nil {
-		return nil, err
-	}
-
-	if err := ch.Qos(workers, 0, false); err != nil {
-		return nil, err
+	if publisher == nil && deadLetterExchange != "" {
+		return nil, errors.New("dead-lettering needs a publisher")
 	}
 
 	return &Consumer{
-		ch:        ch,
-		queueName: queueName,
-		workers:   workers,
-		sem:       make(chan struct{}, workers),
+		conn:       conn,
+		publisher:  publisher,
+		queueName:  queueName,
+		deadLetter: deadLetterExchange,
+		workers:    workers,
+		sem:        make(chan struct{}, workers),
 	}, nil
 }
 
+// ErrRequeue returned (or wrapped) by a Handler puts the message back on
+// the queue instead of dropping it.
+var ErrRequeue = errors.New("requeue message")
+
 type Handler interface {
 	Handle(ctx context.Context, msg amqp.Delivery) error
 }
 
+// Consumer hands messages to handler until ctx is done. When the channel
+// or the connection drops it waits for the connection to come back and
+// consumes again; unacked messages are redelivered by the broker.
 func (c *Consumer) Consumer(ctx context.Context, handler Handler) error {
 	c.consumerTag = uuid.NewString()
 
-	msgs, err := c.ch.Consume(
+	go func() {
+		<-ctx.Done()
+		// it only stop the delivery not cancel the cancel
+		c.cancel()
+	}()
+
+	for {
+		err := c.consume(ctx, handler)
+		if ctx.Err() != nil {
+			break
+		}
+		log.Printf("consumer stopped, resuming: %v", err)
+
+		select {
+		case <-time.After(minReconnectDelay):
+		case <-ctx.Done():
+		}
+		if err := c.conn.WaitConnected(ctx); err != nil {
+			break
+		}
+		// the queue may have been deleted while the connection stayed up
+		if err := c.conn.Redeclare(); err != nil {
+			log.Printf("failed to redeclare topology: %v", err)
+		}
+	}
+
+	c.wg.Wait()
+	return nil
+}
+
+// consume runs one session on a fresh channel until its deliveries stop
+// or the broker cancels the consumer, e.g. because the queue was deleted or
+// moved to another node.
+func (c *Consumer) consume(ctx context.Context, handler Handler) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return err
+	}
+	if err := ch.Qos(c.workers, 0, false); err != nil {
+		_ = ch.Close()
+		return err
+	}
+	cancels := ch.NotifyCancel(make(chan string, 1))
+
+	msgs, err := ch.Consume(
 		c.queueName,
 		c.consumerTag,
 		false,
@@ -57,48 +112,111 @@ func (c *Consumer) Consumer(ctx context.Context, handler Handler) error {
 		false,
 		nil,
 	)
-
 	if err != nil {
+		_ = ch.Close()
 		return err
 	}
 
-	go func() {
-		<-ctx.Done()
-		// it only stop the delivery not cancel the cancel
-		_ = c.ch.Cancel(c.consumerTag, false)
-	}()
+	c.mu.Lock()
+	c.ch = ch
+	c.mu.Unlock()
+	// ctx may have ended before the channel was set
+	if ctx.Err() != nil {
+		c.cancel()
+	}
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				// on shutdown the channel stays open for the acks of running
+				// handlers
+				if ctx.Err() == nil {
+					_ = ch.Close()
+					return errors.New("delivery channel closed")
+				}
+				return nil
+			}
+			c.dispatch(ctx, handler, msg)
 
-	for msg := range msgs {
-		c.sem <- struct{}{}
-		c.wg.Add(1)
+		case tag, ok := <-cancels:
+			// during shutdown the deliveries end on their own
+			if !ok || ctx.Err() != nil {
+				cancels = nil
+				continue
+			}
+			// handlers still running cannot ack on a closed channel, the
+			// broker redelivers their messages
+			_ = ch.Close()
+			return fmt.Errorf("consumer %s cancelled by the broker", tag)
+		}
+	}
+}
 
-		go func(m amqp.Delivery) {
-			defer c.wg.Done()
-			defer func() { <-c.sem }()
+// dispatch hands a message to handler once a worker slot is free and acks,
+// requeues or rejects it from the result.
+func (c *Consumer) dispatch(ctx context.Context, handler Handler, msg amqp.Delivery) {
+	c.sem <- struct{}{}
+	c.wg.Add(1)
+
+	go func(m amqp.Delivery) {
+		defer c.wg.Done()
+		defer func() { <-c.sem }()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("message handler panicked: %v\n%s", r, debug.Stack())
+				c.reject(m, fmt.Sprintf("panic: %v", r))
+			}
+		}()
 
-			msgCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
-			defer cancel()
+		msgCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+		defer cancel()
 
-			if err := handler.Handle(msgCtx, m); err != nil {
-				log.Printf("message failed: %v", err)
-				_ = m.Nack(false, false)
+		if err := handler.Handle(msgCtx, m); err != nil {
+			if errors.Is(err, ErrRequeue) {
+				_ = m.Nack(false, true)
 				return
 			}
+			log.Printf("message failed: %v", err)
+			c.reject(m, err.Error())
+			return
+		}
 
-			_ = m.Ack(false)
-
-		}(msg)
+		_ = m.Ack(false)
+	}(msg)
+}
 
+// reject moves a failed message to the dead-letter exchange with the
+// reason, or drops it when there is none. The message is only acked once
+// the broker confirmed the dead letter; when that fails it goes back to
+// the queue rather than being lost.
+func (c *Consumer) reject(m amqp.Delivery, reason string) {
+	if c.deadLetter == "" {
+		_ = m.Nack(false, false)
+		return
 	}
 
-	c.wg.Wait()
-	return nil
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.publisher.PublishMessage(ctx, c.deadLetter, m.RoutingKey, deadLetterPublishing(m, reason)); err != nil {
+		log.Printf("failed to dead-letter message, requeueing: %v", err)
+		_ = m.Nack(false, true)
+		return
+	}
+	_ = m.Ack(false)
 }
 
-func (c *Consumer) Shutdown(ctx context.Context) error {
-	if c.consumerTag != "" {
+// cancel stops deliveries on the current channel.
+func (c *Consumer) cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ch != nil && c.consumerTag != "" {
 		_ = c.ch.Cancel(c.consumerTag, false)
 	}
+}
+
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.cancel()
 
 	done := make(chan struct{})
 
@@ -109,6 +227,11 @@ func (c *Consumer) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.ch == nil {
+			return nil
+		}
 		return c.ch.Close()
 	case <-ctx.Done():
 		return ctx.Err()
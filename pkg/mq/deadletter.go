@@ -0,0 +1,181 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Headers set on dead-lettered messages.
+const (
+	HeaderFailureReason = "x-failure-reason"
+	HeaderFailedAt      = "x-failed-at"
+)
+
+var ErrNoDeadLetterQueue = errors.New("dead-letter queue is not configured")
+
+func declareDeadLetters(ch *amqp.Channel, rmqCfg *config.RabbitMQConfig) error {
+	if rmqCfg.DeadLetterExchange == "" || rmqCfg.DeadLetterQueue == "" {
+		return nil
+	}
+	if err := ch.ExchangeDeclare(
+		rmqCfg.DeadLetterExchange,
+		amqp.ExchangeFanout,
+		true, false, false, false, nil,
+	); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(
+		rmqCfg.DeadLetterQueue,
+		true, false, false, false, nil,
+	); err != nil {
+		return err
+	}
+	return ch.QueueBind(rmqCfg.DeadLetterQueue, "", rmqCfg.DeadLetterExchange, false, nil)
+}
+
+// deadLetterPublishing copies m for the dead-letter exchange, recording
+// why it failed.
+func deadLetterPublishing(m amqp.Delivery, reason string) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	headers[HeaderFailureReason] = reason
+	headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	return amqp.Publishing{
+		Headers:     headers,
+		ContentType: m.ContentType,
+		Priority:    m.Priority,
+		MessageId:   m.MessageId,
+		Body:        m.Body,
+	}
+}
+
+// DeadLetter is a message parked in the dead-letter queue.
+type DeadLetter struct {
+	Reason   string
+	FailedAt time.Time
+	Priority uint8
+	Body     []byte
+}
+
+// DeadLetters inspects and drains the dead-letter queue.
+type DeadLetters struct {
+	conn      *Connection
+	publisher *Publisher // confirms replays before they leave the queue
+	cfg       *config.RabbitMQConfig
+}
+
+func NewDeadLetters(conn *Connection, publisher *Publisher, rmqCfg *config.RabbitMQConfig) *DeadLetters {
+	return &DeadLetters{conn: conn, publisher: publisher, cfg: rmqCfg}
+}
+
+// List returns up to limit messages from the head of the queue without
+// removing them. They are fetched and requeued, so RabbitMQ marks them
+// redelivered.
+func (d *DeadLetters) List(limit int) ([]DeadLetter, int, error) {
+	var letters []DeadLetter
+	total, err := d.drain(limit, func(ch *amqp.Channel, m amqp.Delivery) error {
+		letters = append(letters, toDeadLetter(m))
+		return nil
+	})
+	return letters, total, err
+}
+
+// Replay publishes up to limit messages (0 = all) back to the main
+// exchange and removes them from the queue once the broker confirmed the
+// publish. It returns how many were replayed.
+func (d *DeadLetters) Replay(ctx context.Context, limit int) (int, error) {
+	replayed := 0
+	_, err := d.drain(limit, func(ch *amqp.Channel, m amqp.Delivery) error {
+		headers := amqp.Table{}
+		for k, v := range m.Headers {
+			headers[k] = v
+		}
+		delete(headers, HeaderFailureReason)
+		delete(headers, HeaderFailedAt)
+
+		if err := d.publisher.PublishMessage(ctx, d.cfg.ExchangeName, d.cfg.RoutingKey, amqp.Publishing{
+			Headers:     headers,
+			ContentType: m.ContentType,
+			Priority:    m.Priority,
+			MessageId:   m.MessageId,
+			Body:        m.Body,
+		}); err != nil {
+			return err
+		}
+		replayed++
+		return m.Ack(false)
+	})
+	return replayed, err
+}
+
+// Purge drops every message in the queue and returns how many there were.
+func (d *DeadLetters) Purge() (int, error) {
+	if d.cfg.DeadLetterQueue == "" {
+		return 0, ErrNoDeadLetterQueue
+	}
+	ch, err := d.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	return ch.QueuePurge(d.cfg.DeadLetterQueue, false)
+}
+
+// drain gets up to limit messages (0 = all present when it starts) and
+// passes them to fn. Messages fn does not ack go back to the queue when the
+// channel closes. It returns the queue depth.
+func (d *DeadLetters) drain(limit int, fn func(*amqp.Channel, amqp.Delivery) error) (int, error) {
+	if d.cfg.DeadLetterQueue == "" {
+		return 0, ErrNoDeadLetterQueue
+	}
+	ch, err := d.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(d.cfg.DeadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+	n := q.Messages
+	if limit > 0 {
+		n = min(n, limit)
+	}
+
+	for range n {
+		m, ok, err := ch.Get(d.cfg.DeadLetterQueue, false)
+		if err != nil {
+			return q.Messages, err
+		}
+		if !ok {
+			break
+		}
+		if err := fn(ch, m); err != nil {
+			return q.Messages, fmt.Errorf("dead letter: %w", err)
+		}
+	}
+	return q.Messages, nil
+}
+
+func toDeadLetter(m amqp.Delivery) DeadLetter {
+	letter := DeadLetter{
+		Priority: m.Priority,
+		Body:     m.Body,
+	}
+	if reason, ok := m.Headers[HeaderFailureReason].(string); ok {
+		letter.Reason = reason
+	}
+	if at, ok := m.Headers[HeaderFailedAt].(string); ok {
+		letter.FailedAt, _ = time.Parse(time.RFC3339, at)
+	}
+	return letter
+}
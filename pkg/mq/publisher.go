@@ -3,56 +3,199 @@ package mq
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrNacked     = errors.New("message was nacked by the broker")
+	ErrUnroutable = errors.New("message could not be routed to a queue")
+)
+
+// returnTTL drops returns nobody claimed, e.g. because the publish gave up
+// waiting for its confirm. Claims happen right after the confirm.
+const returnTTL = time.Minute
+
+// pendingReturn is a return waiting to be claimed by its publish.
+type pendingReturn struct {
+	ret amqp.Return
+	at  time.Time
+}
+
 type Publisher struct {
-	ch         *amqp.Channel            // AMQP channel for publishing messages
-	confirms   <-chan amqp.Confirmation // Channel to receive publish confirmations
-	exchange   string                   // Exchange to publish messages to
-	routingKey string                   // Routing key for the messages
+	conn           *Connection   // connection to (re)open channels on
+	exchange       string        // Exchange to publish messages to
+	routingKey     string        // Routing key for the messages
+	confirmTimeout time.Duration // How long to wait for the broker to confirm a message
+
+	mu       sync.Mutex
+	ch       *amqp.Channel            // AMQP channel for publishing messages
+	returns  <-chan amqp.Return       // Unroutable mandatory messages sent back by the broker
+	returned map[string]pendingReturn // Returns not yet claimed, by message ID
 }
 
-func NewPublisher(conn *amqp.Connection, exchange string, routingkey string) (*Publisher, error) {
+func NewPublisher(conn *Connection, exchange string, routingkey string, confirmTimeout time.Duration) (*Publisher, error) {
 	if conn == nil {
 		return nil, errors.New("AMQP connection is nil ")
 	}
-	ch, err := conn.Channel()
-	if err != nil {
-		return nil, err
+	p := &Publisher{
+		conn:           conn,
+		exchange:       exchange,
+		routingKey:     routingkey,
+		confirmTimeout: confirmTimeout,
+		returned:       make(map[string]pendingReturn),
 	}
-	if err := ch.Confirm(false); err != nil {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.channel(); err != nil {
 		return nil, err
 	}
-	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 100))
+	return p, nil
+}
+
+// Publish sends body with the given AMQP priority and returns once the
+// broker confirmed it. Priorities only take effect on queues declared with
+// x-max-priority.
+func (p *Publisher) Publish(ctx context.Context, body []byte, priority uint8) error {
+	return p.publish(ctx, p.exchange, p.routingKey, amqp.Publishing{Priority: priority, Body: body})
+}
+
+// PublishToQueue sends body straight to queue through the default
+// exchange, e.g. to park a message in a retry queue.
+func (p *Publisher) PublishToQueue(ctx context.Context, queue string, body []byte, priority uint8) error {
+	return p.publish(ctx, "", queue, amqp.Publishing{Priority: priority, Body: body})
+}
 
-	return &Publisher{
-		ch:         ch,
-		confirms:   confirms,
-		exchange:   exchange,
-		routingKey: routingkey,
-	}, nil
+// PublishMessage sends msg to exchange with routingKey, keeping its
+// headers and properties, and returns once the broker confirmed it.
+func (p *Publisher) PublishMessage(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	return p.publish(ctx, exchange, routingKey, msg)
 }
 
-func (p *Publisher) Publish(ctx context.Context, body []byte) error {
-	if p.ch == nil {
-		return errors.New("AMQP chanel is nil ")
+// PublishDelayed sends body to queue with a message TTL of delay, so a
+// queue that dead-letters expired messages hands it on after the delay.
+func (p *Publisher) PublishDelayed(ctx context.Context, queue string, body []byte, priority uint8, delay time.Duration) error {
+	return p.publish(ctx, "", queue, amqp.Publishing{
+		Priority:   priority,
+		Body:       body,
+		Expiration: strconv.FormatInt(max(delay.Milliseconds(), 1), 10),
+	})
+}
+
+// publish sends msg as a mandatory message and waits for its confirm. The
+// lock only covers the send, so concurrent publishes wait for their
+// confirms in parallel; each is matched by its delivery tag.
+func (p *Publisher) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	id := uuid.NewString()
+	msg.MessageId = id
+	if msg.ContentType == "" {
+		msg.ContentType = "application/json"
+	}
+
+	p.mu.Lock()
+	ch, err := p.channel()
+	if err != nil {
+		p.mu.Unlock()
+		return err
 	}
-	return p.ch.PublishWithContext(
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(
 		ctx,
-		p.exchange,
-		p.routingKey,
-		false,
+		exchange,
+		routingKey,
+		true,
 		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
+		msg,
 	)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if p.confirmTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.confirmTimeout)
+		defer cancel()
+	}
+	acked, err := confirm.WaitContext(ctx)
+	// the broker sends a return before the confirm of the same message
+	ret, returned := p.takeReturn(id)
+	if err != nil {
+		return fmt.Errorf("waiting for publish confirm: %w", err)
+	}
+	if returned {
+		return fmt.Errorf("%w: %s", ErrUnroutable, ret.ReplyText)
+	}
+	if !acked {
+		return ErrNacked
+	}
+	return nil
+}
+
+// takeReturn collects the returns received so far and claims the one of
+// message id, if any.
+func (p *Publisher) takeReturn(id string) (amqp.Return, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.collectReturns()
+	pending, ok := p.returned[id]
+	delete(p.returned, id)
+	return pending.ret, ok
+}
+
+// collectReturns moves pending returns into p.returned without blocking
+// and drops the ones left unclaimed for returnTTL. p.mu must be held.
+func (p *Publisher) collectReturns() {
+	now := time.Now()
+	for id, pending := range p.returned {
+		if now.Sub(pending.at) > returnTTL {
+			delete(p.returned, id)
+		}
+	}
+	for {
+		select {
+		case ret, ok := <-p.returns:
+			if !ok {
+				return
+			}
+			p.returned[ret.MessageId] = pendingReturn{ret: ret, at: now}
+		default:
+			return
+		}
+	}
+}
+
+// channel returns the publishing channel, opening a new one when the last
+// was closed, e.g. because the connection was replaced. p.mu must be held.
+func (p *Publisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+	// keep returns from the previous channel before replacing it
+	p.collectReturns()
+	p.ch = ch
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, 100))
+	return ch, nil
 }
 
 func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.ch != nil {
 		return p.ch.Close()
 	}
@@ -0,0 +1,193 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var ErrDisconnected = errors.New("rabbitmq is disconnected")
+
+// Connection keeps an AMQP connection open for the lifetime of the
+// process. When the broker drops it, it redials with backoff and declares
+// the topology again; publishers and consumers open fresh channels from it.
+type Connection struct {
+	cfg *config.RabbitMQConfig
+
+	mu      sync.RWMutex
+	conn    *amqp.Connection
+	changed chan struct{} // closed and replaced on every state change
+	closed  bool
+}
+
+// Dial connects and declares the topology, retrying a few times like
+// NewConn, then keeps the connection alive in the background.
+func Dial(rmqCfg *config.RabbitMQConfig) (*Connection, error) {
+	conn, err := NewConn(rmqCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetupTopology(conn, rmqCfg); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	c := &Connection{
+		cfg:     rmqCfg,
+		conn:    conn,
+		changed: make(chan struct{}),
+	}
+	go c.watch(conn)
+	return c, nil
+}
+
+// Connected reports whether the connection is currently up.
+func (c *Connection) Connected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn != nil && !c.conn.IsClosed()
+}
+
+// Channel opens a channel on the current connection.
+func (c *Connection) Channel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrDisconnected
+	}
+	return conn.Channel()
+}
+
+// QueueDepth returns how many ready messages the queues hold together.
+func (c *Connection) QueueDepth(queues ...string) (int, error) {
+	ch, err := c.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	total := 0
+	for _, name := range queues {
+		q, err := ch.QueueDeclarePassive(name, true, false, false, false, nil)
+		if err != nil {
+			return 0, err
+		}
+		total += q.Messages
+	}
+	return total, nil
+}
+
+// WaitConnected blocks until the connection is up or ctx is done.
+func (c *Connection) WaitConnected(ctx context.Context) error {
+	for {
+		c.mu.RLock()
+		up := c.conn != nil && !c.conn.IsClosed()
+		changed := c.changed
+		c.mu.RUnlock()
+		if up {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Redeclare runs the topology setup again, e.g. after a queue was deleted
+// while the connection stayed up.
+func (c *Connection) Redeclare() error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil || conn.IsClosed() {
+		return ErrDisconnected
+	}
+	return SetupTopology(conn, c.cfg)
+}
+
+func (c *Connection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// watch waits for conn to drop and replaces it, until Close is called.
+func (c *Connection) watch(conn *amqp.Connection) {
+	for {
+		amqpErr, ok := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+		if !ok || amqpErr == nil {
+			// closed on purpose
+			c.mu.RLock()
+			closed := c.closed
+			c.mu.RUnlock()
+			if closed {
+				return
+			}
+		}
+		log.Printf("rabbitmq connection lost: %v", amqpErr)
+
+		c.setConn(nil)
+		conn = c.redial()
+		if conn == nil {
+			return
+		}
+		c.setConn(conn)
+		log.Printf("rabbitmq reconnected")
+	}
+}
+
+// redial dials until it succeeds and the topology is declared, nil when
+// the connection was closed meanwhile.
+func (c *Connection) redial() *amqp.Connection {
+	delay := minReconnectDelay
+	for {
+		time.Sleep(delay)
+
+		c.mu.RLock()
+		closed := c.closed
+		c.mu.RUnlock()
+		if closed {
+			return nil
+		}
+
+		conn, err := amqp.Dial(c.cfg.BrokerLink)
+		if err == nil {
+			if err = SetupTopology(conn, c.cfg); err == nil {
+				return conn
+			}
+			_ = conn.Close()
+		}
+		log.Printf("rabbitmq reconnect failed, retrying in %v: %v", delay, err)
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (c *Connection) setConn(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed && conn != nil {
+		_ = conn.Close()
+		conn = nil
+	}
+	c.conn = conn
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
@@ -0,0 +1,72 @@
+package mq
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// RetryQueue holds messages for Delay, after which RabbitMQ dead-letters
+// them back to the main exchange.
+type RetryQueue struct {
+	Name  string
+	Delay time.Duration
+}
+
+// RetryQueues returns the delay queue of each retry in order, with the
+// delay doubling from RetryBaseDelay. The delay is part of the name since
+// the TTL of an existing queue cannot be changed.
+func RetryQueues(rmqCfg *config.RabbitMQConfig) []RetryQueue {
+	if rmqCfg.RetryBaseDelay <= 0 {
+		return nil
+	}
+	var queues []RetryQueue
+	delay := rmqCfg.RetryBaseDelay
+	for range rmqCfg.RetryMaxAttempts - 1 {
+		queues = append(queues, RetryQueue{
+			Name:  fmt.Sprintf("%s.retry.%s", rmqCfg.QueueName, delay),
+			Delay: delay,
+		})
+		delay *= 2
+	}
+	return queues
+}
+
+// DeferQueue holds messages that must wait before they are consumed again,
+// e.g. for a host to become free. Each message carries its own TTL, after
+// which RabbitMQ dead-letters it back to the main exchange. Expired
+// messages only leave from the head of the queue, so a message may wait
+// up to the longest delay ahead of it.
+func DeferQueue(rmqCfg *config.RabbitMQConfig) string {
+	return rmqCfg.QueueName + ".deferred"
+}
+
+func declareRetryQueues(ch *amqp091.Channel, rmqCfg *config.RabbitMQConfig) error {
+	if _, err := ch.QueueDeclare(
+		DeferQueue(rmqCfg),
+		true, false, false, false,
+		amqp091.Table{
+			"x-dead-letter-exchange":    rmqCfg.ExchangeName,
+			"x-dead-letter-routing-key": rmqCfg.RoutingKey,
+		},
+	); err != nil {
+		return err
+	}
+
+	for _, q := range RetryQueues(rmqCfg) {
+		if _, err := ch.QueueDeclare(
+			q.Name,
+			true, false, false, false,
+			amqp091.Table{
+				"x-message-ttl":             q.Delay.Milliseconds(),
+				"x-dead-letter-exchange":    rmqCfg.ExchangeName,
+				"x-dead-letter-routing-key": rmqCfg.RoutingKey,
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/config"
+)
+
+func TestRetryQueues(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     time.Duration
+		want     []RetryQueue
+	}{
+		{"disabled", 4, 0, nil},
+		{"single attempt", 1, 10 * time.Second, nil},
+		{"doubling", 4, 10 * time.Second, []RetryQueue{
+			{Name: "scrape.retry.10s", Delay: 10 * time.Second},
+			{Name: "scrape.retry.20s", Delay: 20 * time.Second},
+			{Name: "scrape.retry.40s", Delay: 40 * time.Second},
+		}},
+		{"sub-second", 2, 500 * time.Millisecond, []RetryQueue{
+			{Name: "scrape.retry.500ms", Delay: 500 * time.Millisecond},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := RetryQueues(&config.RabbitMQConfig{
+			QueueName:        "scrape",
+			RetryMaxAttempts: tt.attempts,
+			RetryBaseDelay:   tt.base,
+		})
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d queues, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: queue %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
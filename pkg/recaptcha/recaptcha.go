@@ -0,0 +1,53 @@
+package recaptcha
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/captcha"
+	"github.com/Alkush-Pipania/Scrapper/pkg/siteverify"
+)
+
+const DefaultVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+var _ captcha.Verifier = (*Client)(nil)
+
+// Client verifies reCAPTCHA v3 tokens. v3 never shows a challenge, instead
+// every token carries a score from 0.0 (bot) to 1.0 (human) and the action
+// the page executed.
+type Client struct {
+	sv       *siteverify.Client
+	action   string
+	minScore float64
+}
+
+// New creates a reCAPTCHA v3 client accepting tokens issued for action and
+// scored at least minScore. An empty verifyURL uses Google's siteverify
+// endpoint.
+func New(secret, verifyURL, action string, minScore float64) *Client {
+	if verifyURL == "" {
+		verifyURL = DefaultVerifyURL
+	}
+	return &Client{
+		sv:       siteverify.New("recaptcha", secret, verifyURL),
+		action:   action,
+		minScore: minScore,
+	}
+}
+
+func (c *Client) Verify(ctx context.Context, token, ip string) error {
+	result, err := c.sv.Verify(ctx, token, ip)
+	if err != nil {
+		return err
+	}
+
+	// a token minted for another action on the site must not pass here
+	if result.Action != c.action {
+		return fmt.Errorf("action %q does not match %q", result.Action, c.action)
+	}
+	if result.Score < c.minScore {
+		return fmt.Errorf("score %.1f below threshold %.1f", result.Score, c.minScore)
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+// apiKeyIndex maps key IDs to their hash so admins can manage keys without
+// ever seeing the secret again.
+const apiKeyIndex = "apikeys"
+
+func (r *Client) apiKeyKey(hash string) string {
+	return "apikey:" + hash
+}
+
+func (r *Client) quotaKeys(keyID string, now time.Time) (daily, monthly string) {
+	return "quota:" + keyID + ":d:" + now.Format("20060102"),
+		"quota:" + keyID + ":m:" + now.Format("200601")
+}
+
+func (r *Client) SaveAPIKey(key *auth.APIKey) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, r.apiKeyKey(key.Hash), data, 0)
+	pipe.HSet(ctx, apiKeyIndex, key.ID, key.Hash)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *Client) GetAPIKeyByHash(hash string) (*auth.APIKey, error) {
+	val, err := r.rdb.Get(context.Background(), r.apiKeyKey(hash)).Result()
+	if err == redis.Nil {
+		return nil, auth.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key auth.APIKey
+	if err := json.Unmarshal([]byte(val), &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *Client) GetAPIKey(id string) (*auth.APIKey, error) {
+	hash, err := r.rdb.HGet(context.Background(), apiKeyIndex, id).Result()
+	if err == redis.Nil {
+		return nil, auth.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetAPIKeyByHash(hash)
+}
+
+func (r *Client) ListAPIKeys() ([]*auth.APIKey, error) {
+	ctx := context.Background()
+
+	index, err := r.rdb.HGetAll(ctx, apiKeyIndex).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*auth.APIKey, 0, len(index))
+	for _, hash := range index {
+		key, err := r.GetAPIKeyByHash(hash)
+		if err == auth.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *Client) DeleteAPIKey(id string) error {
+	ctx := context.Background()
+
+	hash, err := r.rdb.HGet(ctx, apiKeyIndex, id).Result()
+	if err == redis.Nil {
+		return auth.ErrKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Del(ctx, r.apiKeyKey(hash))
+	pipe.HDel(ctx, apiKeyIndex, id)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// consumeQuota atomically adds n submissions to the daily and monthly
+// counters unless that would exceed a limit (0 means unlimited).
+// Returns 0 on success, 1 when the daily and 2 when the monthly limit hit.
+var consumeQuota = redis.NewScript(`
+local n = tonumber(ARGV[1])
+local dailyLimit = tonumber(ARGV[2])
+local monthlyLimit = tonumber(ARGV[3])
+local daily = tonumber(redis.call("GET", KEYS[1]) or "0")
+local monthly = tonumber(redis.call("GET", KEYS[2]) or "0")
+if dailyLimit > 0 and daily + n > dailyLimit then
+	return 1
+end
+if monthlyLimit > 0 and monthly + n > monthlyLimit then
+	return 2
+end
+redis.call("INCRBY", KEYS[1], n)
+redis.call("EXPIRE", KEYS[1], tonumber(ARGV[4]))
+redis.call("INCRBY", KEYS[2], n)
+redis.call("EXPIRE", KEYS[2], tonumber(ARGV[5]))
+return 0
+`)
+
+// ConsumeQuota records n submissions for the key or returns a
+// *auth.QuotaError when that would exceed one of its quotas.
+func (r *Client) ConsumeQuota(key *auth.APIKey, n int) error {
+	now := time.Now().UTC()
+	daily, monthly := r.quotaKeys(key.ID, now)
+
+	res, err := consumeQuota.Run(context.Background(), r.rdb,
+		[]string{daily, monthly},
+		n, key.DailyQuota, key.MonthlyQuota,
+		int((48 * time.Hour).Seconds()), int((32 * 24 * time.Hour).Seconds()),
+	).Int()
+	if err != nil {
+		return err
+	}
+
+	switch res {
+	case 1:
+		return &auth.QuotaError{
+			Period:  "daily",
+			Limit:   key.DailyQuota,
+			ResetAt: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+		}
+	case 2:
+		return &auth.QuotaError{
+			Period:  "monthly",
+			Limit:   key.MonthlyQuota,
+			ResetAt: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return nil
+}
+
+// refundQuota takes n submissions back from the counters that still exist,
+// never going below zero. A counter that rolled over meanwhile is left alone.
+var refundQuota = redis.NewScript(`
+local n = tonumber(ARGV[1])
+for _, key in ipairs(KEYS) do
+	local count = tonumber(redis.call("GET", key) or "0")
+	if count > 0 then
+		redis.call("DECRBY", key, math.min(count, n))
+	end
+end
+return 0
+`)
+
+// RefundQuota gives back n submissions counted by ConsumeQuota for
+// submissions that never got enqueued.
+func (r *Client) RefundQuota(keyID string, n int) error {
+	daily, monthly := r.quotaKeys(keyID, time.Now().UTC())
+	return refundQuota.Run(context.Background(), r.rdb, []string{daily, monthly}, n).Err()
+}
+
+// GetUsage returns the submissions counted for the key today and this month.
+func (r *Client) GetUsage(keyID string) (*auth.Usage, error) {
+	daily, monthly := r.quotaKeys(keyID, time.Now().UTC())
+
+	vals, err := r.rdb.MGet(context.Background(), daily, monthly).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Usage{
+		Daily:   toInt(vals[0]),
+		Monthly: toInt(vals[1]),
+	}, nil
+}
+
+func toInt(v interface{}) int {
+	s, _ := v.(string)
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+func (r *Client) batchKey(id string) string {
+	return "batch:" + id
+}
+
+// CreateBatch stores every job of a batch plus the batch -> job IDs index
+// in a single round trip.
+func (r *Client) CreateBatch(batchID string, jobs []*domain.Job) error {
+	ctx := context.Background()
+	pipe := r.rdb.TxPipeline()
+
+	now := time.Now().UTC()
+	ids := make([]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status == "" {
+			job.Status = domain.StatusPending
+		}
+		job.BatchID = batchID
+		if job.CreatedAt.IsZero() {
+			job.CreatedAt = now
+		}
+		job.UpdatedAt = job.CreatedAt
+
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to save job: %w", err)
+		}
+		pipe.Set(ctx, r.key(job.ID), data, r.ttl)
+		r.indexJob(ctx, pipe, job)
+		ids = append(ids, job.ID)
+	}
+
+	r.pruneIndexes(ctx, pipe, jobs...)
+
+	pipe.RPush(ctx, r.batchKey(batchID), ids...)
+	pipe.Expire(ctx, r.batchKey(batchID), r.ttl)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetBatch returns the jobs of a batch in submission order. Jobs that have
+// already expired are skipped.
+func (r *Client) GetBatch(batchID string) ([]*domain.Job, error) {
+	ctx := context.Background()
+
+	ids, err := r.rdb.LRange(ctx, r.batchKey(batchID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, domain.ErrBatchNotFound
+	}
+	return r.GetJobs(ids)
+}
+
+// GetJobs loads several jobs with a single MGET.
+func (r *Client) GetJobs(ids []string) ([]*domain.Job, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.key(id)
+	}
+
+	vals, err := r.rdb.MGet(context.Background(), keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*domain.Job, 0, len(vals))
+	for _, val := range vals {
+		raw, ok := val.(string)
+		if !ok {
+			continue
+		}
+		var job domain.Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+func (r *Client) cacheKey(urlHash, fingerprint string) string {
+	return "cache:" + urlHash + ":" + fingerprint
+}
+
+// CacheResult stores the latest successful scrape of a URL with the
+// options of fingerprint for ttl.
+func (r *Client) CacheResult(urlHash, fingerprint string, data *domain.ScrapedData, ttl time.Duration) error {
+	entry, err := json.Marshal(&domain.CachedResult{
+		Data:     data,
+		CachedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(context.Background(), r.cacheKey(urlHash, fingerprint), entry, ttl).Err()
+}
+
+// GetCachedResult returns the cached scrape of a URL with the options of
+// fingerprint or ErrKeyNotFound.
+func (r *Client) GetCachedResult(urlHash, fingerprint string) (*domain.CachedResult, error) {
+	val, err := r.rdb.Get(context.Background(), r.cacheKey(urlHash, fingerprint)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var entry domain.CachedResult
+	if err := json.Unmarshal([]byte(val), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
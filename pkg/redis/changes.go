@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/redis/go-redis/v9"
+)
+
+// historyScope keeps the snapshots and changes of a URL apart per API key
+// and options fingerprint.
+func historyScope(keyID, fingerprint, hash string) string {
+	if keyID == "" {
+		keyID = "anonymous"
+	}
+	return keyID + ":" + fingerprint + ":" + hash
+}
+
+func (r *Client) snapshotKey(keyID, fingerprint, hash string) string {
+	return "snapshot:" + historyScope(keyID, fingerprint, hash)
+}
+
+func (r *Client) changesKey(keyID, fingerprint, hash string) string {
+	return "changes:" + historyScope(keyID, fingerprint, hash)
+}
+
+// SwapSnapshot stores snap as the latest state of its URL for its key and
+// options, and returns the one it replaced, nil for the first scrape. Both
+// happen atomically so concurrent scrapes of a URL each see a distinct
+// predecessor.
+func (r *Client) SwapSnapshot(snap *domain.Snapshot, retention time.Duration) (*domain.Snapshot, error) {
+	ctx := context.Background()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.snapshotKey(snap.APIKeyID, snap.OptionsFingerprint, snap.URLHash)
+	pipe := r.rdb.TxPipeline()
+	prevCmd := pipe.Get(ctx, key)
+	pipe.Set(ctx, key, data, retention)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	val, err := prevCmd.Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var prev domain.Snapshot
+	if err := json.Unmarshal([]byte(val), &prev); err != nil {
+		return nil, err
+	}
+	return &prev, nil
+}
+
+func (r *Client) GetSnapshot(keyID, fingerprint, hash string) (*domain.Snapshot, error) {
+	val, err := r.rdb.Get(context.Background(), r.snapshotKey(keyID, fingerprint, hash)).Result()
+	if err == redis.Nil {
+		return nil, domain.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snap domain.Snapshot
+	if err := json.Unmarshal([]byte(val), &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// AddChange records a change found by a scrape of keyID, keeping the
+// latest maxRecords per URL.
+func (r *Client) AddChange(keyID string, change *domain.Change, retention time.Duration, maxRecords int) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	key := r.changesKey(keyID, change.OptionsFingerprint, change.URLHash)
+	pipe := r.rdb.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(maxRecords-1))
+	pipe.Expire(ctx, key, retention)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ListChanges returns up to limit changes of a URL seen by keyID with the
+// options of fingerprint, newest first.
+func (r *Client) ListChanges(keyID, fingerprint, hash string, limit int) ([]*domain.Change, error) {
+	vals, err := r.rdb.LRange(context.Background(), r.changesKey(keyID, fingerprint, hash), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*domain.Change, 0, len(vals))
+	for _, val := range vals {
+		var change domain.Change
+		if err := json.Unmarshal([]byte(val), &change); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+	return changes, nil
+}
@@ -14,6 +14,10 @@ var (
 	ErrKeyNotFound = redis.Nil
 )
 
+// maxTxRetries bounds how often an optimistic transaction starts over when
+// the watched key changed under it.
+const maxTxRetries = 5
+
 type Client struct {
 	rdb *redis.Client
 	ttl time.Duration
@@ -58,30 +62,121 @@ func (r *Client) key(id string) string {
 	return "job:" + id
 }
 
-func (r *Client) save(job *domain.Job) error {
+func (r *Client) CreateJob(job *domain.Job) error {
+	ctx := context.Background()
+
+	if job.Status == "" {
+		job.Status = domain.StatusPending
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+	job.UpdatedAt = job.CreatedAt
 	data, err := json.Marshal(job)
 	if err != nil {
 		return fmt.Errorf("failed to save job: %w", err)
 	}
-	return r.rdb.Set(context.Background(), r.key(job.ID), data, r.ttl).Err()
-}
 
-func (r *Client) CreateJob(id, url string) error {
-	job := &domain.Job{
-		ID:     id,
-		URL:    url,
-		Status: domain.StatusPending,
-	}
-	return r.save(job)
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, r.key(job.ID), data, r.ttl)
+	r.indexJob(ctx, pipe, job)
+	r.pruneIndexes(ctx, pipe, job)
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
 func (r *Client) UpdateStatus(id string, status domain.JobStatus) error {
-	job, err := r.GetJob(id)
-	if err != nil {
-		return err
-	}
-	job.Status = status
-	return r.save(job)
+	return r.transition(id, func(job *domain.Job) error {
+		job.Status = status
+		return nil
+	})
+}
+
+// StartJob moves a job to processing unless it was cancelled or finished
+// meanwhile.
+func (r *Client) StartJob(id string) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Status == domain.StatusCancelled {
+			return domain.ErrJobCancelled
+		}
+		// a duplicate delivery of a job that already ran
+		if job.Status.Terminal() {
+			return domain.ErrJobFinished
+		}
+		job.Status = domain.StatusProcessing
+		job.NextRetryAt = nil
+		return nil
+	})
+}
+
+// ScheduleRetry puts a job whose run failed transiently back to pending
+// until its retry at next.
+func (r *Client) ScheduleRetry(id string, errMsg string, timeline []domain.Stage, next time.Time) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Status == domain.StatusCancelled {
+			return domain.ErrJobCancelled
+		}
+		job.Status = domain.StatusPending
+		job.Retries++
+		job.NextRetryAt = &next
+		job.LastError = errMsg
+		job.Timeline = timeline
+		return nil
+	})
+}
+
+// CancelJob marks a pending or processing job as cancelled and returns it.
+func (r *Client) CancelJob(id string) (*domain.Job, error) {
+	var cancelled *domain.Job
+	err := r.transition(id, func(job *domain.Job) error {
+		if job.Status.Terminal() {
+			return domain.ErrJobFinished
+		}
+		job.Status = domain.StatusCancelled
+		cancelled = job
+		return nil
+	})
+	return cancelled, err
+}
+
+// RetryJob puts a failed or completed job back to pending under the same
+// ID. The previous run is moved to the attempt history; opts, when set,
+// replace the job's options.
+func (r *Client) RetryJob(id string, opts *domain.ScrapeOptions) (*domain.Job, error) {
+	var retried *domain.Job
+	err := r.transition(id, func(job *domain.Job) error {
+		if job.Status != domain.StatusFailed && job.Status != domain.StatusCompleted {
+			return domain.ErrNotRetryable
+		}
+		if job.Attempt == 0 {
+			job.Attempt = 1
+		}
+		job.Attempts = append(job.Attempts, domain.Attempt{
+			Attempt:   job.Attempt,
+			Status:    job.Status,
+			Error:     job.Error,
+			Options:   job.Options,
+			RetriedAt: time.Now().UTC(),
+		})
+		job.Attempt++
+		if opts != nil {
+			job.Options = *opts
+		}
+
+		job.Status = domain.StatusPending
+		job.Result = nil
+		job.Error = ""
+		job.Timeline = nil
+		job.CachedAt = nil
+		job.Callback = nil
+		job.Retries = 0
+		job.NextRetryAt = nil
+		job.LastError = ""
+		job.Republished = 0
+		retried = job
+		return nil
+	})
+	return retried, err
 }
 
 func (r *Client) GetJob(id string) (*domain.Job, error) {
@@ -99,22 +194,94 @@ func (r *Client) GetJob(id string) (*domain.Job, error) {
 	return &job, nil
 }
 
-func (r *Client) FailJob(id string, errMsg string) error {
-	job, err := r.GetJob(id)
-	if err != nil {
-		return err
-	}
-	job.Status = domain.StatusFailed
-	job.Error = errMsg
-	return r.save(job)
+func (r *Client) FailJob(id string, errMsg string, timeline []domain.Stage) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Status == domain.StatusCancelled {
+			return domain.ErrJobCancelled
+		}
+		job.Status = domain.StatusFailed
+		job.Error = errMsg
+		job.Timeline = timeline
+		return nil
+	})
 }
 
-func (r *Client) UpdateResult(id string, data *domain.ScrapedData) error {
-	job, err := r.GetJob(id)
-	if err != nil {
+func (r *Client) UpdateResult(id string, data *domain.ScrapedData, timeline []domain.Stage) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Status == domain.StatusCancelled {
+			return domain.ErrJobCancelled
+		}
+		job.Status = domain.StatusCompleted
+		job.Result = data
+		job.Timeline = timeline
+		return nil
+	})
+}
+
+// UpdateCallback records the webhook outcome of the given attempt of a
+// job. It returns ErrJobChanged when the job was retried or reset
+// meanwhile, so the outcome never lands on a later run.
+func (r *Client) UpdateCallback(id string, attempt int, delivery *domain.CallbackDelivery) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Attempt != attempt || !job.Status.Terminal() {
+			return domain.ErrJobChanged
+		}
+		job.Callback = delivery
+		return nil
+	})
+}
+
+// transition applies a status change to a stored job and announces the new
+// state to anyone subscribed to the job's events. The job is watched while
+// apply runs, so a concurrent change makes it start over on the fresh state
+// instead of being overwritten.
+func (r *Client) transition(id string, apply func(job *domain.Job) error) error {
+	ctx := context.Background()
+	key := r.key(id)
+
+	var updated *domain.Job
+	txf := func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return domain.ErrJobNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var job domain.Job
+		if err := json.Unmarshal([]byte(val), &job); err != nil {
+			return err
+		}
+		prev := job.Status
+		if err := apply(&job); err != nil {
+			return err
+		}
+		job.UpdatedAt = time.Now().UTC()
+		data, err := json.Marshal(&job)
+		if err != nil {
+			return fmt.Errorf("failed to save job: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, r.ttl)
+			r.reindexStatus(ctx, pipe, &job, prev)
+			return nil
+		})
+		updated = &job
 		return err
 	}
-	job.Status = domain.StatusCompleted
-	job.Result = data
-	return r.save(job)
+
+	for range maxTxRetries {
+		err := r.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// events are best effort, the stored job stays the source of truth
+		_ = r.publishEvent(updated)
+		return nil
+	}
+	return redis.TxFailedErr
 }
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+// A cancel racing a completion or a failure must leave exactly one of them
+// applied, and the loser must be told so.
+func TestCancelRacesFinish(t *testing.T) {
+	r := newTestClient(t)
+
+	finishers := []struct {
+		name   string
+		status domain.JobStatus
+		finish func(id string) error
+	}{
+		{"result", domain.StatusCompleted, func(id string) error {
+			return r.UpdateResult(id, &domain.ScrapedData{Title: "done"}, nil)
+		}},
+		{"failure", domain.StatusFailed, func(id string) error {
+			return r.FailJob(id, "boom", nil)
+		}},
+	}
+
+	for _, f := range finishers {
+		t.Run(f.name, func(t *testing.T) {
+			for range 50 {
+				job := createTestJob(t, r, domain.StatusProcessing, time.Now().UTC())
+
+				var cancelErr, finishErr error
+				var wg sync.WaitGroup
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					_, cancelErr = r.CancelJob(job.ID)
+				}()
+				go func() {
+					defer wg.Done()
+					finishErr = f.finish(job.ID)
+				}()
+				wg.Wait()
+
+				got, err := r.GetJob(job.ID)
+				if err != nil {
+					t.Fatalf("GetJob: %v", err)
+				}
+				switch {
+				case cancelErr == nil && errors.Is(finishErr, domain.ErrJobCancelled):
+					if got.Status != domain.StatusCancelled || got.Result != nil {
+						t.Errorf("cancel won but job is %s with result %v", got.Status, got.Result)
+					}
+				case finishErr == nil && errors.Is(cancelErr, domain.ErrJobFinished):
+					if got.Status != f.status {
+						t.Errorf("finish won but job is %s", got.Status)
+					}
+				default:
+					t.Fatalf("cancel error = %v, finish error = %v, want exactly one to win", cancelErr, finishErr)
+				}
+				assertIndexed(t, r, job.ID, got.Status)
+			}
+		})
+	}
+}
+
+// A webhook outcome recorded after the job was retried must not bring the
+// previous run back.
+func TestUpdateCallbackAfterRetry(t *testing.T) {
+	r := newTestClient(t)
+
+	job := createTestJob(t, r, domain.StatusProcessing, time.Now().UTC())
+	job.Attempt = 1
+	if err := r.UpdateResult(job.ID, &domain.ScrapedData{Title: "done"}, nil); err != nil {
+		t.Fatalf("UpdateResult: %v", err)
+	}
+	if _, err := r.RetryJob(job.ID, nil); err != nil {
+		t.Fatalf("RetryJob: %v", err)
+	}
+
+	delivery := &domain.CallbackDelivery{Status: domain.CallbackDelivered, Attempts: 1}
+	if err := r.UpdateCallback(job.ID, job.Attempt, delivery); !errors.Is(err, domain.ErrJobChanged) {
+		t.Fatalf("UpdateCallback error = %v, want ErrJobChanged", err)
+	}
+
+	got, err := r.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if got.Status != domain.StatusPending || got.Result != nil || got.Callback != nil {
+		t.Errorf("job = %s with result %v and callback %v, want a clean pending retry", got.Status, got.Result, got.Callback)
+	}
+	assertIndexed(t, r, job.ID, domain.StatusPending)
+
+	// the retried run records its own outcome
+	if err := r.UpdateResult(job.ID, &domain.ScrapedData{Title: "again"}, nil); err != nil {
+		t.Fatalf("UpdateResult: %v", err)
+	}
+	if err := r.UpdateCallback(job.ID, got.Attempt, delivery); err != nil {
+		t.Fatalf("UpdateCallback: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+)
+
+func (r *Client) eventsChannel(id string) string {
+	return "job-events:" + id
+}
+
+func (r *Client) publishEvent(job *domain.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Publish(context.Background(), r.eventsChannel(job.ID), data).Err()
+}
+
+// SubscribeJob streams every state change of a job until ctx is done or the
+// returned close func is called. The subscription is confirmed before
+// SubscribeJob returns, so a GetJob afterwards cannot miss a transition.
+func (r *Client) SubscribeJob(ctx context.Context, id string) (<-chan *domain.Job, func() error, error) {
+	sub := r.rdb.Subscribe(ctx, r.eventsChannel(id))
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, nil, err
+	}
+
+	out := make(chan *domain.Job)
+	go func() {
+		defer close(out)
+		for msg := range sub.Channel() {
+			var job domain.Job
+			if err := json.Unmarshal([]byte(msg.Payload), &job); err != nil {
+				continue
+			}
+			select {
+			case out <- &job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, sub.Close, nil
+}
+
+const cancelChannel = "job-cancel"
+
+// PublishCancel asks whichever worker runs the job to abort it.
+func (r *Client) PublishCancel(id string) error {
+	return r.rdb.Publish(context.Background(), cancelChannel, id).Err()
+}
+
+// SubscribeCancels streams the IDs of cancelled jobs until ctx is done.
+func (r *Client) SubscribeCancels(ctx context.Context) (<-chan string, error) {
+	sub := r.rdb.Subscribe(ctx, cancelChannel)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+func (r *Client) idempotencyKey(key string) string {
+	return "idem:" + key
+}
+
+// ReserveIdempotencyKey binds key to jobID for ttl. When the key is already
+// bound it returns the job ID stored by the first request instead.
+func (r *Client) ReserveIdempotencyKey(key, jobID string, ttl time.Duration) (string, error) {
+	ctx := context.Background()
+
+	ok, err := r.rdb.SetNX(ctx, r.idempotencyKey(key), jobID, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return "", nil
+	}
+	return r.rdb.Get(ctx, r.idempotencyKey(key)).Result()
+}
+
+// ReleaseIdempotencyKey frees a key whose submission failed so the client
+// can retry it.
+func (r *Client) ReleaseIdempotencyKey(key string) error {
+	return r.rdb.Del(context.Background(), r.idempotencyKey(key)).Err()
+}
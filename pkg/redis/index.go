@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/Alkush-Pipania/Scrapper/pkg/urlutil"
+	"github.com/redis/go-redis/v9"
+)
+
+// Secondary indexes are sorted sets scored by job creation time (unix
+// microseconds), so every index can be range-scanned by time and paginated
+// the same way.
+const (
+	createdIndex = "jobs:created"
+	scanChunk    = 100
+	maxScans     = 20
+)
+
+func (r *Client) statusIndex(status domain.JobStatus) string {
+	return "jobs:status:" + string(status)
+}
+
+func (r *Client) domainIndex(host string) string {
+	return "jobs:domain:" + host
+}
+
+func (r *Client) keyIndex(keyID string) string {
+	return "jobs:key:" + keyID
+}
+
+func jobScore(job *domain.Job) float64 {
+	return float64(job.CreatedAt.UnixMicro())
+}
+
+// indexJob adds a new job to the created, status, domain and API key
+// indexes.
+func (r *Client) indexJob(ctx context.Context, pipe redis.Pipeliner, job *domain.Job) {
+	z := redis.Z{Score: jobScore(job), Member: job.ID}
+	pipe.ZAdd(ctx, createdIndex, z)
+	pipe.ZAdd(ctx, r.statusIndex(job.Status), z)
+
+	if host := urlutil.Host(job.URL); host != "" {
+		pipe.ZAdd(ctx, r.domainIndex(host), z)
+		pipe.Expire(ctx, r.domainIndex(host), r.ttl)
+	}
+	if job.APIKeyID != "" {
+		pipe.ZAdd(ctx, r.keyIndex(job.APIKeyID), z)
+		pipe.Expire(ctx, r.keyIndex(job.APIKeyID), r.ttl)
+	}
+}
+
+// pruneIndexes trims index entries that outlived the job TTL from the
+// global indexes and the ones jobs were just added to.
+func (r *Client) pruneIndexes(ctx context.Context, pipe redis.Pipeliner, jobs ...*domain.Job) {
+	expired := strconv.FormatInt(time.Now().Add(-r.ttl).UnixMicro(), 10)
+
+	pipe.ZRemRangeByScore(ctx, createdIndex, "-inf", expired)
+	for _, status := range domain.Statuses {
+		pipe.ZRemRangeByScore(ctx, r.statusIndex(status), "-inf", expired)
+	}
+
+	pruned := make(map[string]struct{})
+	prune := func(index string) {
+		if _, ok := pruned[index]; ok {
+			return
+		}
+		pruned[index] = struct{}{}
+		pipe.ZRemRangeByScore(ctx, index, "-inf", expired)
+	}
+	for _, job := range jobs {
+		if host := urlutil.Host(job.URL); host != "" {
+			prune(r.domainIndex(host))
+		}
+		if job.APIKeyID != "" {
+			prune(r.keyIndex(job.APIKeyID))
+		}
+	}
+}
+
+// reindexStatus moves a job between status indexes.
+func (r *Client) reindexStatus(ctx context.Context, pipe redis.Pipeliner, job *domain.Job, prev domain.JobStatus) {
+	if prev == job.Status {
+		return
+	}
+	pipe.ZRem(ctx, r.statusIndex(prev), job.ID)
+	pipe.ZAdd(ctx, r.statusIndex(job.Status), redis.Z{Score: jobScore(job), Member: job.ID})
+}
+
+// ListJobs returns jobs matching the filter, newest first, plus the cursor
+// for the next page ("" when there is none).
+func (r *Client) ListJobs(f domain.JobFilter) ([]*domain.Job, string, error) {
+	ctx := context.Background()
+
+	// scan the most selective index and filter the rest in memory
+	index := createdIndex
+	host := urlutil.NormalizeHost(f.Domain)
+	switch {
+	case host != "":
+		index = r.domainIndex(host)
+	case f.APIKeyID != "":
+		index = r.keyIndex(f.APIKeyID)
+	case f.Status != "":
+		index = r.statusIndex(f.Status)
+	}
+
+	max := "+inf"
+	var afterScore float64
+	var afterID string
+	if f.Cursor != "" {
+		var err error
+		afterScore, afterID, err = parseCursor(f.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		max = strconv.FormatFloat(afterScore, 'f', 0, 64)
+	}
+	min := "-inf"
+	if !f.Since.IsZero() {
+		min = strconv.FormatInt(f.Since.UnixMicro(), 10)
+	}
+
+	var (
+		jobs    []*domain.Job
+		stale   []interface{}
+		offset  int64
+		scanned redis.Z
+		more    bool
+	)
+	for scan := 0; scan < maxScans && len(jobs) < f.Limit; scan++ {
+		entries, err := r.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:     index,
+			Start:   min,
+			Stop:    max,
+			ByScore: true,
+			Rev:     true,
+			Offset:  offset,
+			Count:   scanChunk,
+		}).Result()
+		if err != nil {
+			return nil, "", err
+		}
+		offset += int64(len(entries))
+		more = len(entries) == scanChunk
+
+		ids := make([]string, 0, len(entries))
+		for _, e := range entries {
+			ids = append(ids, e.Member.(string))
+		}
+		found, err := r.getJobsByID(ids)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for i, e := range entries {
+			id := ids[i]
+			// members sharing the cursor score that sort at or after it were
+			// already returned on the previous page
+			if f.Cursor != "" && e.Score == afterScore && id >= afterID {
+				continue
+			}
+			if len(jobs) == f.Limit {
+				more = true
+				break
+			}
+			scanned = e
+
+			job, ok := found[id]
+			if !ok {
+				stale = append(stale, id)
+				continue
+			}
+			if matches(job, f, host) {
+				jobs = append(jobs, job)
+			}
+		}
+		if !more {
+			break
+		}
+	}
+
+	if len(stale) > 0 {
+		// the jobs expired, drop their index entries lazily
+		r.rdb.ZRem(ctx, index, stale...)
+	}
+
+	next := ""
+	if more && scanned.Member != nil {
+		next = formatCursor(scanned)
+	}
+	return jobs, next, nil
+}
+
+func (r *Client) getJobsByID(ids []string) (map[string]*domain.Job, error) {
+	jobs, err := r.GetJobs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*domain.Job, len(jobs))
+	for _, job := range jobs {
+		found[job.ID] = job
+	}
+	return found, nil
+}
+
+func matches(job *domain.Job, f domain.JobFilter, host string) bool {
+	if job.APIKeyID != f.APIKeyID {
+		return false
+	}
+	if f.Status != "" && job.Status != f.Status {
+		return false
+	}
+	if host != "" && urlutil.Host(job.URL) != host {
+		return false
+	}
+	return true
+}
+
+func formatCursor(z redis.Z) string {
+	return fmt.Sprintf("%.0f-%v", z.Score, z.Member)
+}
+
+func parseCursor(cursor string) (float64, string, error) {
+	score, id, ok := strings.Cut(cursor, "-")
+	if !ok || id == "" {
+		return 0, "", domain.ErrInvalidCursor
+	}
+	s, err := strconv.ParseInt(score, 10, 64)
+	if err != nil {
+		return 0, "", domain.ErrInvalidCursor
+	}
+	return float64(s), id, nil
+}
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []redis.Z{
+		{Score: 1760720000000, Member: "3f1c2a9e-7b4d-4e8a-9c61-0d2f5b7e8a10"},
+		{Score: 0, Member: "job"},
+	}
+
+	for _, z := range tests {
+		cursor := formatCursor(z)
+		score, id, err := parseCursor(cursor)
+		if err != nil {
+			t.Fatalf("parseCursor(%q): %v", cursor, err)
+		}
+		if score != z.Score || id != z.Member {
+			t.Errorf("parseCursor(%q) = %v, %q, want %v, %q", cursor, score, id, z.Score, z.Member)
+		}
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	for _, cursor := range []string{
+		"",
+		"1760720000000",
+		"1760720000000-",
+		"-3f1c2a9e",
+		"abc-3f1c2a9e",
+		"1.5-3f1c2a9e",
+	} {
+		if _, _, err := parseCursor(cursor); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("parseCursor(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
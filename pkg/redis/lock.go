@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewLock extends a lock only while owner still holds it.
+var renewLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock takes or renews the named lock for owner. It reports whether
+// owner holds the lock afterwards; the lock expires after ttl unless renewed.
+func (r *Client) AcquireLock(name, owner string, ttl time.Duration) (bool, error) {
+	ctx := context.Background()
+	key := "lock:" + name
+
+	ok, err := r.rdb.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+
+	renewed, err := renewLock.Run(ctx, r.rdb, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+// ReleaseLock gives up the named lock if owner holds it.
+func (r *Client) ReleaseLock(name, owner string) error {
+	return releaseLock.Run(context.Background(), r.rdb, []string{"lock:" + name}, owner).Err()
+}
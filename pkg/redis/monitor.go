@@ -0,0 +1,184 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	monitorsAll = "monitors:all" // every monitor, scored by creation
+	monitorsDue = "monitors:due" // active monitors, scored by next run
+)
+
+func (r *Client) monitorKey(id string) string {
+	return "monitor:" + id
+}
+
+// monitorsByKey indexes the monitors of an API key, scored by creation.
+func (r *Client) monitorsByKey(keyID string) string {
+	return "monitors:key:" + keyID
+}
+
+// SaveMonitor stores a monitor without expiry and schedules it unless it
+// is paused.
+func (r *Client) SaveMonitor(m *monitor.Monitor) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, r.monitorKey(m.ID), data, 0)
+	z := redis.Z{Score: float64(m.CreatedAt.UnixMicro()), Member: m.ID}
+	pipe.ZAdd(ctx, monitorsAll, z)
+	pipe.ZAdd(ctx, r.monitorsByKey(m.APIKeyID), z)
+	r.scheduleMonitor(ctx, pipe, m)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *Client) GetMonitor(id string) (*monitor.Monitor, error) {
+	val, err := r.rdb.Get(context.Background(), r.monitorKey(id)).Result()
+	if err == redis.Nil {
+		return nil, monitor.ErrMonitorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m monitor.Monitor
+	if err := json.Unmarshal([]byte(val), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMonitors returns the monitors of an API key, newest first.
+func (r *Client) ListMonitors(keyID string) ([]*monitor.Monitor, error) {
+	ctx := context.Background()
+
+	ids, err := r.rdb.ZRevRange(ctx, r.monitorsByKey(keyID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*monitor.Monitor{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.monitorKey(id)
+	}
+	vals, err := r.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := make([]*monitor.Monitor, 0, len(vals))
+	for _, val := range vals {
+		s, ok := val.(string)
+		if !ok {
+			continue
+		}
+		var m monitor.Monitor
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, &m)
+	}
+	return monitors, nil
+}
+
+// UpdateMonitor applies a change to a stored monitor. The write is retried
+// when the monitor changes concurrently, so a pause from the API is never
+// lost to a run recorded by the scheduler.
+func (r *Client) UpdateMonitor(id string, apply func(m *monitor.Monitor) error) (*monitor.Monitor, error) {
+	ctx := context.Background()
+	key := r.monitorKey(id)
+
+	var updated *monitor.Monitor
+	txf := func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return monitor.ErrMonitorNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var m monitor.Monitor
+		if err := json.Unmarshal([]byte(val), &m); err != nil {
+			return err
+		}
+		if err := apply(&m); err != nil {
+			return err
+		}
+		data, err := json.Marshal(&m)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, 0)
+			r.scheduleMonitor(ctx, pipe, &m)
+			return nil
+		})
+		updated = &m
+		return err
+	}
+
+	for range maxTxRetries {
+		err := r.rdb.Watch(ctx, txf, key)
+		if err != redis.TxFailedErr {
+			return updated, err
+		}
+	}
+	return nil, redis.TxFailedErr
+}
+
+func (r *Client) DeleteMonitor(id string) error {
+	ctx := context.Background()
+
+	m, err := r.GetMonitor(id)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.rdb.TxPipeline()
+	del := pipe.Del(ctx, r.monitorKey(id))
+	pipe.ZRem(ctx, monitorsAll, id)
+	pipe.ZRem(ctx, r.monitorsByKey(m.APIKeyID), id)
+	pipe.ZRem(ctx, monitorsDue, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if del.Val() == 0 {
+		return monitor.ErrMonitorNotFound
+	}
+	return nil
+}
+
+// scheduleMonitor keeps the due index in line with the monitor's state.
+func (r *Client) scheduleMonitor(ctx context.Context, pipe redis.Pipeliner, m *monitor.Monitor) {
+	if m.Paused {
+		pipe.ZRem(ctx, monitorsDue, m.ID)
+		return
+	}
+	pipe.ZAdd(ctx, monitorsDue, redis.Z{Score: float64(m.NextRunAt.UnixMilli()), Member: m.ID})
+}
+
+// DueMonitors returns up to limit IDs of active monitors whose next run is
+// at or before now.
+func (r *Client) DueMonitors(now time.Time, limit int) ([]string, error) {
+	return r.rdb.ZRangeByScore(context.Background(), monitorsDue, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: int64(limit),
+	}).Result()
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/monitor"
+)
+
+func TestListMonitorsByKey(t *testing.T) {
+	r := newTestClient(t)
+	now := time.Now().UTC()
+
+	for i, m := range []*monitor.Monitor{
+		{ID: "a", APIKeyID: "k1"},
+		{ID: "b", APIKeyID: "k2"},
+		{ID: "c", APIKeyID: "k1"},
+	} {
+		m.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		m.NextRunAt = now
+		if err := r.SaveMonitor(m); err != nil {
+			t.Fatalf("SaveMonitor: %v", err)
+		}
+	}
+	if err := r.DeleteMonitor("a"); err != nil {
+		t.Fatalf("DeleteMonitor: %v", err)
+	}
+
+	tests := []struct {
+		keyID string
+		want  []string
+	}{
+		{"k1", []string{"c"}},
+		{"k2", []string{"b"}},
+		{"k3", nil},
+	}
+	for _, tt := range tests {
+		monitors, err := r.ListMonitors(tt.keyID)
+		if err != nil {
+			t.Fatalf("ListMonitors(%s): %v", tt.keyID, err)
+		}
+		var ids []string
+		for _, m := range monitors {
+			ids = append(ids, m.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("ListMonitors(%s) = %v, want %v", tt.keyID, ids, tt.want)
+		}
+	}
+}
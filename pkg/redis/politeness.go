@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeHostSlot leases one of the concurrent slots of a host and enforces
+// the minimum delay since the last lease. Leases expire on their own so a
+// crashed worker cannot hold a slot forever.
+var takeHostSlot = redis.NewScript(`
+local limit = tonumber(ARGV[2])
+local delay = tonumber(ARGV[3])
+local lease = tonumber(ARGV[4])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if limit > 0 and redis.call("ZCARD", KEYS[1]) >= limit then
+	return {0, 0}
+end
+
+if delay > 0 then
+	local next = tonumber(redis.call("GET", KEYS[2]) or "0")
+	if next > now then
+		return {0, next - now}
+	end
+	redis.call("SET", KEYS[2], now + delay, "PX", delay)
+end
+
+redis.call("ZADD", KEYS[1], now + lease, ARGV[1])
+redis.call("PEXPIRE", KEYS[1], lease)
+return {1, 0}
+`)
+
+func (r *Client) hostSlotsKey(host string) string {
+	return "politeness:" + host + ":slots"
+}
+
+func (r *Client) hostNextKey(host string) string {
+	return "politeness:" + host + ":next"
+}
+
+// AcquireHostSlot leases a slot on host for holder when fewer than limit
+// are taken (0 = no limit) and at least delay passed since the previous
+// lease. When it is not acquired, wait is the remaining delay or 0 when
+// all slots are taken.
+func (r *Client) AcquireHostSlot(host, holder string, limit int, delay, lease time.Duration) (bool, time.Duration, error) {
+	res, err := takeHostSlot.Run(context.Background(), r.rdb,
+		[]string{r.hostSlotsKey(host), r.hostNextKey(host)},
+		holder, limit, delay.Milliseconds(), lease.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func (r *Client) ReleaseHostSlot(host, holder string) error {
+	return r.rdb.ZRem(context.Background(), r.hostSlotsKey(host), holder).Err()
+}
@@ -0,0 +1,39 @@
+package redis
+
+import "testing"
+
+func TestTakeTokens(t *testing.T) {
+	r := newTestClient(t)
+
+	// refills one token a minute, so the test never sees a refill
+	tests := []struct {
+		name      string
+		n         int
+		allowed   bool
+		remaining int
+	}{
+		{"single", 1, true, 9},
+		{"several", 5, true, 4},
+		{"more than left", 5, false, 4},
+		{"rest", 4, true, 0},
+		{"empty", 1, false, 0},
+	}
+
+	for _, tt := range tests {
+		res, err := r.TakeTokens("test", tt.n, 1, 10)
+		if err != nil {
+			t.Fatalf("%s: TakeTokens: %v", tt.name, err)
+		}
+		if res.Allowed != tt.allowed || res.Remaining != tt.remaining {
+			t.Errorf("%s: allowed, remaining = %v, %d, want %v, %d", tt.name, res.Allowed, res.Remaining, tt.allowed, tt.remaining)
+		}
+	}
+
+	res, err := r.TakeTokens("other", 11, 1, 10)
+	if err != nil {
+		t.Fatalf("TakeTokens: %v", err)
+	}
+	if res.Allowed {
+		t.Error("took more tokens than the burst")
+	}
+}
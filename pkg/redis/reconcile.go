@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/redis/go-redis/v9"
+)
+
+// StaleJobs returns up to limit jobs in status whose last update is before
+// cutoff, oldest first. Only jobs created before cutoff can qualify, so the
+// status index is scanned up to it.
+func (r *Client) StaleJobs(status domain.JobStatus, cutoff time.Time, limit int) ([]*domain.Job, error) {
+	ctx := context.Background()
+	max := strconv.FormatInt(cutoff.UnixMicro(), 10)
+
+	var stale []*domain.Job
+	var offset int64
+	for scans := 0; scans < maxScans && len(stale) < limit; scans++ {
+		ids, err := r.rdb.ZRangeByScore(ctx, r.statusIndex(status), &redis.ZRangeBy{
+			Min:    "-inf",
+			Max:    max,
+			Offset: offset,
+			Count:  scanChunk,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		offset += int64(len(ids))
+
+		jobs, err := r.GetJobs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			if job.Status == status && job.LastUpdate().Before(cutoff) {
+				stale = append(stale, job)
+			}
+		}
+	}
+	return stale[:min(len(stale), limit)], nil
+}
+
+// RepublishStaleJob counts a re-publish of a job still pending since before
+// cutoff and returns it, ErrJobChanged when it moved on meanwhile. The check
+// runs inside transition, so a worker starting the job concurrently is never
+// overwritten.
+func (r *Client) RepublishStaleJob(id string, cutoff time.Time) (*domain.Job, error) {
+	var republished *domain.Job
+	err := r.transition(id, func(job *domain.Job) error {
+		if job.Status != domain.StatusPending || !job.LastUpdate().Before(cutoff) {
+			return domain.ErrJobChanged
+		}
+		job.Republished++
+		republished = job
+		return nil
+	})
+	return republished, err
+}
+
+// FailStaleJob fails a job still in status since before cutoff, returning
+// ErrJobChanged when it moved on meanwhile. Like RepublishStaleJob it checks
+// and writes atomically.
+func (r *Client) FailStaleJob(id string, status domain.JobStatus, cutoff time.Time, reason string) error {
+	return r.transition(id, func(job *domain.Job) error {
+		if job.Status != status || !job.LastUpdate().Before(cutoff) {
+			return domain.ErrJobChanged
+		}
+		job.Status = domain.StatusFailed
+		job.Error = reason
+		return nil
+	})
+}
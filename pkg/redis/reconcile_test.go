@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/Alkush-Pipania/Scrapper/internal/domain/scrape"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return &Client{rdb: rdb, ttl: time.Hour}
+}
+
+// createTestJob stores a job in status last updated at updated.
+func createTestJob(t *testing.T, r *Client, status domain.JobStatus, updated time.Time) *domain.Job {
+	t.Helper()
+	job := &domain.Job{
+		ID:        uuid.NewString(),
+		URL:       "https://example.com",
+		Status:    status,
+		CreatedAt: updated,
+	}
+	if err := r.CreateJob(job); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	return job
+}
+
+// assertIndexed checks the job sits in the status index of its status only.
+func assertIndexed(t *testing.T, r *Client, id string, status domain.JobStatus) {
+	t.Helper()
+	for _, s := range domain.Statuses {
+		_, err := r.rdb.ZScore(t.Context(), r.statusIndex(s), id).Result()
+		if in := err == nil; in != (s == status) {
+			t.Errorf("job %v in %s index = %v, want %v", id, s, in, s == status)
+		}
+	}
+}
+
+func TestStaleTransitions(t *testing.T) {
+	r := newTestClient(t)
+	now := time.Now().UTC()
+	cutoff := now.Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		status    domain.JobStatus
+		updated   time.Time
+		republish error
+		fail      error
+	}{
+		{"stale pending", domain.StatusPending, now.Add(-time.Hour), nil, nil},
+		{"recent pending", domain.StatusPending, now, domain.ErrJobChanged, domain.ErrJobChanged},
+		{"stale processing", domain.StatusProcessing, now.Add(-time.Hour), domain.ErrJobChanged, domain.ErrJobChanged},
+		{"completed", domain.StatusCompleted, now.Add(-time.Hour), domain.ErrJobChanged, domain.ErrJobChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := createTestJob(t, r, tt.status, tt.updated)
+			if _, err := r.RepublishStaleJob(job.ID, cutoff); !errors.Is(err, tt.republish) {
+				t.Errorf("RepublishStaleJob error = %v, want %v", err, tt.republish)
+			}
+
+			job = createTestJob(t, r, tt.status, tt.updated)
+			if err := r.FailStaleJob(job.ID, domain.StatusPending, cutoff, "stuck"); !errors.Is(err, tt.fail) {
+				t.Errorf("FailStaleJob error = %v, want %v", err, tt.fail)
+			}
+		})
+	}
+}
+
+// A worker starting a job while the reconciler republishes it must never
+// end up back in pending.
+func TestRepublishRacesStart(t *testing.T) {
+	r := newTestClient(t)
+	cutoff := time.Now().UTC().Add(-time.Minute)
+
+	for range 50 {
+		job := createTestJob(t, r, domain.StatusPending, cutoff.Add(-time.Hour))
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := r.StartJob(job.ID); err != nil {
+				t.Errorf("StartJob: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := r.RepublishStaleJob(job.ID, cutoff)
+			if err != nil && !errors.Is(err, domain.ErrJobChanged) {
+				t.Errorf("RepublishStaleJob: %v", err)
+			}
+		}()
+		wg.Wait()
+
+		got, err := r.GetJob(job.ID)
+		if err != nil {
+			t.Fatalf("GetJob: %v", err)
+		}
+		if got.Status != domain.StatusProcessing {
+			t.Errorf("status = %s, want processing", got.Status)
+		}
+		assertIndexed(t, r, job.ID, domain.StatusProcessing)
+	}
+}
@@ -0,0 +1,74 @@
+package siteverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client posts tokens to a siteverify endpoint. Turnstile, hCaptcha and
+// reCAPTCHA share the same request format and the same base response.
+type Client struct {
+	name      string
+	secret    string
+	verifyURL string
+	client    *http.Client
+}
+
+// New creates a client for the provider called name, used in errors.
+func New(name, secret, verifyURL string) *Client {
+	return &Client{
+		name:      name,
+		secret:    secret,
+		verifyURL: verifyURL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Response holds the fields common to the providers. Score and Action are
+// only set by reCAPTCHA v3.
+type Response struct {
+	Success  bool     `json:"success"`
+	Hostname string   `json:"hostname"`
+	Score    float64  `json:"score"`
+	Action   string   `json:"action"`
+	Errors   []string `json:"error-codes"`
+}
+
+// Verify checks the token and returns the provider's response. A response
+// without success is returned as an error.
+func (c *Client) Verify(ctx context.Context, token, ip string) (*Response, error) {
+	form := url.Values{}
+	form.Set("secret", c.secret)
+	form.Set("response", token)
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	var result Response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !result.Success {
+		return nil, fmt.Errorf("validation failed: %v", result.Errors)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,177 @@
+// Package textdiff compares two texts line by line using the Myers diff
+// algorithm and summarizes the difference.
+package textdiff
+
+import "strings"
+
+const (
+	// maxEdits bounds the work spent on very different texts. Beyond it the
+	// result is estimated from the lines the texts have in common.
+	maxEdits = 500
+	// maxSample is the number of added and removed lines kept as examples.
+	maxSample = 20
+)
+
+// Result summarizes how b differs from a.
+type Result struct {
+	Added      int     `json:"added"`   // lines only in b
+	Removed    int     `json:"removed"` // lines only in a
+	Similarity float64 `json:"similarity"`
+	// AddedLines and RemovedLines hold the first lines of each kind.
+	AddedLines   []string `json:"added_lines,omitempty"`
+	RemovedLines []string `json:"removed_lines,omitempty"`
+	// Approximate is set when the texts were too different for an exact
+	// diff and the counts ignore line order.
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// Changed reports whether the texts differ.
+func (r Result) Changed() bool {
+	return r.Added > 0 || r.Removed > 0
+}
+
+// Compare diffs two texts. Lines are trimmed and blank lines ignored, so
+// reflowed whitespace does not count as a change. Similarity is the share
+// of lines both texts have in common, 1 for identical texts.
+func Compare(a, b string) Result {
+	al, bl := Lines(a), Lines(b)
+
+	// common prefix and suffix never need the diff
+	pre := 0
+	for pre < len(al) && pre < len(bl) && al[pre] == bl[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(al)-pre && suf < len(bl)-pre && al[len(al)-1-suf] == bl[len(bl)-1-suf] {
+		suf++
+	}
+
+	var res Result
+	added, removed, ok := myers(al[pre:len(al)-suf], bl[pre:len(bl)-suf])
+	if !ok {
+		added, removed = multiset(al[pre:len(al)-suf], bl[pre:len(bl)-suf])
+		res.Approximate = true
+	}
+
+	res.Added, res.Removed = len(added), len(removed)
+	res.AddedLines = added[:min(len(added), maxSample)]
+	res.RemovedLines = removed[:min(len(removed), maxSample)]
+
+	total := len(al) + len(bl)
+	if total == 0 {
+		res.Similarity = 1
+	} else {
+		common := total - res.Added - res.Removed
+		res.Similarity = float64(common) / float64(total)
+	}
+	return res
+}
+
+// Lines splits text into trimmed, non-blank lines.
+func Lines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// myers returns the lines removed from a and added in b along a shortest
+// edit script. ok is false when more than maxEdits edits are needed.
+func myers(a, b []string) (added, removed []string, ok bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, nil, true
+	}
+
+	offset := maxEdits + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= maxEdits; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // step down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				added, removed = backtrack(a, b, trace, offset)
+				return added, removed, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+func backtrack(a, b []string, trace [][]int, offset int) (added, removed []string) {
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+		}
+		if x == prevX {
+			added = append(added, b[prevY])
+		} else {
+			removed = append(removed, a[prevX])
+		}
+		x, y = prevX, prevY
+	}
+
+	// backtracking walks the script in reverse
+	reverse(added)
+	reverse(removed)
+	return added, removed
+}
+
+// multiset counts lines present in only one of the texts, ignoring order.
+func multiset(a, b []string) (added, removed []string) {
+	counts := make(map[string]int, len(a))
+	for _, line := range a {
+		counts[line]++
+	}
+	for _, line := range b {
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range a {
+		if counts[line] > 0 {
+			counts[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func reverse(s []string) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,178 @@
+package textdiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       string
+		added      []string
+		removed    []string
+		similarity float64
+	}{
+		{
+			name:       "identical",
+			a:          "one\ntwo\nthree",
+			b:          "one\ntwo\nthree",
+			similarity: 1,
+		},
+		{
+			name:       "both empty",
+			similarity: 1,
+		},
+		{
+			name:       "whitespace and blank lines",
+			a:          "one\ntwo",
+			b:          "  one  \n\n\ttwo\n",
+			similarity: 1,
+		},
+		{
+			name:       "insert in the middle",
+			a:          "one\nthree",
+			b:          "one\ntwo\nthree",
+			added:      []string{"two"},
+			similarity: 0.8,
+		},
+		{
+			name:       "insert into empty text",
+			b:          "one\ntwo",
+			added:      []string{"one", "two"},
+			similarity: 0,
+		},
+		{
+			name:       "append",
+			a:          "one\ntwo",
+			b:          "one\ntwo\nthree\nfour",
+			added:      []string{"three", "four"},
+			similarity: 4.0 / 6,
+		},
+		{
+			name:       "remove",
+			a:          "one\ntwo\nthree",
+			b:          "one\nthree",
+			removed:    []string{"two"},
+			similarity: 0.8,
+		},
+		{
+			name:       "replace",
+			a:          "one\ntwo\nthree",
+			b:          "one\n2\nthree",
+			added:      []string{"2"},
+			removed:    []string{"two"},
+			similarity: 4.0 / 6,
+		},
+		{
+			name:       "interleaved",
+			a:          "a\nb\nc\nd",
+			b:          "a\nx\nc\ny",
+			added:      []string{"x", "y"},
+			removed:    []string{"b", "d"},
+			similarity: 0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.a, tt.b)
+			if !slices.Equal(got.AddedLines, tt.added) || got.Added != len(tt.added) {
+				t.Errorf("added = %d %q, want %q", got.Added, got.AddedLines, tt.added)
+			}
+			if !slices.Equal(got.RemovedLines, tt.removed) || got.Removed != len(tt.removed) {
+				t.Errorf("removed = %d %q, want %q", got.Removed, got.RemovedLines, tt.removed)
+			}
+			if diff := got.Similarity - tt.similarity; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("similarity = %v, want %v", got.Similarity, tt.similarity)
+			}
+			if got.Changed() != (len(tt.added)+len(tt.removed) > 0) {
+				t.Errorf("Changed() = %v", got.Changed())
+			}
+			if got.Approximate {
+				t.Error("exact diff reported as approximate")
+			}
+		})
+	}
+}
+
+func TestCompareSampleLimit(t *testing.T) {
+	got := Compare("", numbered("line", maxSample+10))
+
+	if got.Added != maxSample+10 {
+		t.Errorf("added = %d, want %d", got.Added, maxSample+10)
+	}
+	if len(got.AddedLines) != maxSample {
+		t.Errorf("kept %d added lines, want %d", len(got.AddedLines), maxSample)
+	}
+}
+
+func TestCompareMaxEditsFallback(t *testing.T) {
+	// reversing n lines takes 2(n-1) edits, disjoint texts take 2n
+	n := maxEdits/2 + 2
+
+	tests := []struct {
+		name            string
+		a, b            string
+		added, removed  int
+		wantApproximate bool
+		wantSimilarity  float64
+	}{
+		{
+			name:            "disjoint texts",
+			a:               numbered("old", n),
+			b:               numbered("new", n),
+			added:           n,
+			removed:         n,
+			wantApproximate: true,
+		},
+		{
+			// the same lines in reverse order need too many edits, and the
+			// estimate ignores order
+			name:            "reordered",
+			a:               numbered("line", n),
+			b:               reversed(numbered("line", n)),
+			wantApproximate: true,
+			wantSimilarity:  1,
+		},
+		{
+			name:            "just within the budget",
+			a:               numbered("old", maxEdits/2),
+			b:               numbered("new", maxEdits/2),
+			added:           maxEdits / 2,
+			removed:         maxEdits / 2,
+			wantApproximate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.a, tt.b)
+			if got.Approximate != tt.wantApproximate {
+				t.Errorf("approximate = %v, want %v", got.Approximate, tt.wantApproximate)
+			}
+			if got.Added != tt.added || got.Removed != tt.removed {
+				t.Errorf("added, removed = %d, %d, want %d, %d", got.Added, got.Removed, tt.added, tt.removed)
+			}
+			if got.Similarity != tt.wantSimilarity {
+				t.Errorf("similarity = %v, want %v", got.Similarity, tt.wantSimilarity)
+			}
+		})
+	}
+}
+
+func numbered(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d", prefix, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func reversed(text string) string {
+	lines := strings.Split(text, "\n")
+	slices.Reverse(lines)
+	return strings.Join(lines, "\n")
+}
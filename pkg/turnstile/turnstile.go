@@ -2,61 +2,37 @@ package turnstile
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
+
+	"github.com/Alkush-Pipania/Scrapper/internal/domain/captcha"
+	"github.com/Alkush-Pipania/Scrapper/pkg/siteverify"
 )
 
+const DefaultVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+var _ captcha.Verifier = (*Client)(nil)
+
 type Client struct {
 	secret string
-	client *http.Client
+	sv     *siteverify.Client
 }
 
-func New(secret string) *Client {
+// New creates a Turnstile client. An empty verifyURL uses Cloudflare's
+// siteverify endpoint.
+func New(secret, verifyURL string) *Client {
+	if verifyURL == "" {
+		verifyURL = DefaultVerifyURL
+	}
 	return &Client{
 		secret: secret,
-		client: &http.Client{Timeout: 5 * time.Second},
+		sv:     siteverify.New("turnstile", secret, verifyURL),
 	}
 }
 
-type turnstileResponse struct {
-	Success bool     `json:"success"`
-	Errors  []string `json:"error-codes"`
-}
-
 func (c *Client) Verify(ctx context.Context, token, ip string) error {
 	if c.secret == "" {
 		return nil
 	}
 
-	verifyURL := "https://challenges.cloudflare.com/turnstile/v0/siteverify"
-
-	req, err := http.NewRequestWithContext(ctx, "POST", verifyURL, nil)
-	if err != nil {
-		return err
-	}
-
-	q := req.URL.Query()
-	q.Add("secret", c.secret)
-	q.Add("response", token)
-	q.Add("remoteip", ip)
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to connect to turnstile: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var result turnstileResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if !result.Success {
-		return fmt.Errorf("validation failed: %v", result.Errors)
-	}
-
-	return nil
+	_, err := c.sv.Verify(ctx, token, ip)
+	return err
 }
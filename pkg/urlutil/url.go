@@ -0,0 +1,83 @@
+package urlutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Host returns the lowercased hostname of rawURL without port and leading
+// "www.", or "" when it cannot be parsed.
+func Host(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return NormalizeHost(u.Hostname())
+}
+
+// NormalizeHost lowercases a bare hostname and strips a leading "www.".
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return strings.TrimPrefix(host, "www.")
+}
+
+// trackingParams are dropped from the query when normalizing.
+var trackingParams = []string{"utm_", "fbclid", "gclid", "mc_cid", "mc_eid"}
+
+// Normalize returns a canonical form of rawURL so equivalent links share a
+// cache entry: lowercased scheme and host, no default port, fragment or
+// tracking parameters, sorted query and no trailing slash.
+func Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("url is not absolute: %s", rawURL)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host = host + ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	q := u.Query()
+	for key := range q {
+		for _, p := range trackingParams {
+			if strings.HasPrefix(strings.ToLower(key), p) {
+				q.Del(key)
+				break
+			}
+		}
+	}
+	u.RawQuery = q.Encode() // Encode sorts by key
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	return u.String(), nil
+}
+
+// Hash returns a stable identifier for a normalized URL.
+func Hash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Key normalizes rawURL and returns its hash, falling back to the raw URL
+// when it cannot be normalized.
+func Key(rawURL string) string {
+	normalized, err := Normalize(rawURL)
+	if err != nil {
+		normalized = rawURL
+	}
+	return Hash(normalized)
+}
@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxURLLength caps URLs accepted from clients.
+const MaxURLLength = 2048
+
+// Error reports an invalid request field. Message reads as a predicate of
+// the field, e.g. "is required".
+type Error struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// Errorf builds an Error for field.
+func Errorf(field, format string, args ...any) *Error {
+	return &Error{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Nest prefixes the field of a nested error, e.g. "items[3]" and "url"
+// become "items[3].url". Other errors are returned unchanged.
+func Nest(prefix string, err error) error {
+	ve, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+	field := prefix
+	if ve.Field != "" {
+		field = prefix + "." + ve.Field
+	}
+	return &Error{Field: field, Message: ve.Message}
+}
+
+// HTTPURL checks that raw is an absolute http or https URL with a host.
+func HTTPURL(field, raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return Errorf(field, "is required")
+	}
+	if len(raw) > MaxURLLength {
+		return Errorf(field, "must be at most %d characters", MaxURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Errorf(field, "is not a valid URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Errorf(field, "must be an http or https URL")
+	}
+	if u.Hostname() == "" {
+		return Errorf(field, "must include a host")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHTTPURL(t *testing.T) {
+	tests := []struct {
+		raw string
+		msg string
+	}{
+		{"https://example.com/page?q=1", ""},
+		{"http://localhost:8080", ""},
+		{"", "is required"},
+		{"   ", "is required"},
+		{"https://example.com/" + strings.Repeat("a", MaxURLLength), "must be at most 2048 characters"},
+		{"https://exa mple.com/%zz", "is not a valid URL"},
+		{"ftp://example.com", "must be an http or https URL"},
+		{"example.com", "must be an http or https URL"},
+		{"https:///path", "must include a host"},
+	}
+
+	for _, tt := range tests {
+		err := HTTPURL("url", tt.raw)
+		var msg string
+		if err != nil {
+			var ve *Error
+			if !errors.As(err, &ve) || ve.Field != "url" {
+				t.Fatalf("HTTPURL(%q) = %v, want an error on url", tt.raw, err)
+			}
+			msg = ve.Message
+		}
+		if msg != tt.msg {
+			t.Errorf("HTTPURL(%q) message = %q, want %q", tt.raw, msg, tt.msg)
+		}
+	}
+}
+
+func TestNest(t *testing.T) {
+	other := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"field", Errorf("url", "is required"), "items[3].url is required"},
+		{"no field", &Error{Message: "is invalid"}, "items[3] is invalid"},
+		{"other error", other, "boom"},
+	}
+
+	for _, tt := range tests {
+		if got := Nest("items[3]", tt.err).Error(); got != tt.want {
+			t.Errorf("%s: Nest = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
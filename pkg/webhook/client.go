@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Scrapper-Signature"
+	TimestampHeader = "X-Scrapper-Timestamp"
+	EventHeader     = "X-Scrapper-Event"
+)
+
+type Client struct {
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// Result describes the outcome of a delivery, including every retry.
+type Result struct {
+	Attempts   int
+	StatusCode int
+	Err        error
+}
+
+func New(timeout time.Duration, maxAttempts int) *Client {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Client{
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		baseDelay:   time.Second,
+	}
+}
+
+// Deliver POSTs the payload to url, retrying with exponential backoff on
+// network errors and non-2xx responses. When secret is set the request is
+// signed with HMAC-SHA256 over "<timestamp>.<body>".
+func (c *Client) Deliver(ctx context.Context, url, secret, event string, payload []byte) Result {
+	var res Result
+
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		res.Attempts = attempt
+		res.StatusCode, res.Err = c.send(ctx, url, secret, event, payload)
+		if res.Err == nil {
+			return res
+		}
+		if attempt == c.maxAttempts {
+			break
+		}
+
+		delay := c.baseDelay << (attempt - 1)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			res.Err = ctx.Err()
+			return res
+		}
+	}
+	return res
+}
+
+func (c *Client) send(ctx context.Context, url, secret, event string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, ts)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, ts, payload))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex encoded signature receivers should compare against.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"job_id":"abc"}`)
+
+	// expected values computed independently with HMAC-SHA256
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		payload   []byte
+		want      string
+	}{
+		{"payload", "s3cret", "1760720000", payload, "5bcd2f404bd8058e6a4176b7e88f62d722dee39837c0ddcdaa37cb82b1c3aa60"},
+		{"other timestamp", "s3cret", "1760720001", payload, "408e6cf9a2087f09a7d08ea15abeaa4ce073f1bba27d80ff6f2271c6c893209a"},
+		{"other secret", "other", "1760720000", payload, "f30fb9b47fe8ee58c446ed70214f4938686a389e20cdd36324d139206a3ddd5f"},
+		{"empty payload", "s3cret", "1760720000", nil, "9849a0e017bc85787cc914279c104c46ba149b8a48d2b2e7c76b795161eca88f"},
+	}
+
+	for _, tt := range tests {
+		if got := Sign(tt.secret, tt.timestamp, tt.payload); got != tt.want {
+			t.Errorf("%s: Sign = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	payload := []byte(`{"job_id":"abc"}`)
+
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{"signed", "s3cret"},
+		{"unsigned", ""},
+	}
+
+	for _, tt := range tests {
+		var got *http.Request
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			body, _ = io.ReadAll(r.Body)
+		}))
+
+		res := New(time.Second, 1).Deliver(context.Background(), srv.URL, tt.secret, "job.completed", payload)
+		srv.Close()
+		if res.Err != nil {
+			t.Fatalf("%s: Deliver: %v", tt.name, res.Err)
+		}
+
+		if e := got.Header.Get(EventHeader); e != "job.completed" {
+			t.Errorf("%s: event header = %q", tt.name, e)
+		}
+		sig := got.Header.Get(SignatureHeader)
+		if tt.secret == "" {
+			if sig != "" {
+				t.Errorf("%s: unexpected signature %q", tt.name, sig)
+			}
+			continue
+		}
+		want := "sha256=" + Sign(tt.secret, got.Header.Get(TimestampHeader), body)
+		if sig != want {
+			t.Errorf("%s: signature = %q, want %q", tt.name, sig, want)
+		}
+	}
+}